@@ -0,0 +1,142 @@
+package gioc
+
+import (
+	"reflect"
+	"strings"
+)
+
+const (
+	argumentPrefixService   = "@"
+	argumentPrefixParameter = "#"
+	argumentPrefixLocator   = "locator:"
+	argumentOptionalMarker  = "?"
+	argumentDefaultDelim    = "|"
+	argumentEscape          = "\\"
+)
+
+// argumentDefinition is a parsed element of Factory.Arguments.
+// Supported forms are:
+//
+//	@alias          - service with given alias
+//	?@alias         - service with given alias or zero value (nil) if service is not registered
+//	#param          - Container's parameter
+//	#param|default  - Container's parameter or default value if parameter is not set
+//	?#param         - Container's parameter or zero value if parameter is not set
+//	locator:a,b     - Locator of services with aliases a and b
+//	\@literal       - literal value "@literal", leading backslash escapes prefix, "?" or other backslash
+//	anything else   - literal value
+//
+// Same prefix, optional and default rules apply to prefixes of resolvers registered with
+// Container.RegisterArgumentResolver.
+type argumentDefinition struct {
+	prefix       string
+	resolver     ArgumentResolver
+	name         string
+	optional     bool
+	defaultValue string
+	hasDefault   bool
+}
+
+func (d *argumentDefinition) isLiteral() bool {
+	return nil == d.resolver
+}
+
+func parseArgumentDefinition(resolvers *argumentResolvers, definition string) *argumentDefinition {
+	result := &argumentDefinition{}
+
+	if strings.HasPrefix(definition, argumentEscape) {
+		result.name = definition
+		// Backslash is an escape only before prefix, optional marker or other backslash, so literals like
+		// Windows paths and regular expressions are passed as is
+		if escaped := definition[len(argumentEscape):]; isEscapable(resolvers, escaped) {
+			result.name = escaped
+		}
+
+		return result
+	}
+
+	body := definition
+	optional := false
+	if strings.HasPrefix(body, argumentOptionalMarker) {
+		optional = true
+		body = body[len(argumentOptionalMarker):]
+	}
+
+	prefix, resolver := resolvers.match(body)
+	if nil == resolver {
+		// Definition is a literal value, take it as is (including possible leading "?")
+		result.name = definition
+
+		return result
+	}
+
+	result.prefix = prefix
+	result.resolver = resolver
+	result.optional = optional
+	result.name = body[len(prefix):]
+	if delimPos := strings.Index(result.name, argumentDefaultDelim); delimPos >= 0 {
+		result.defaultValue = result.name[delimPos+len(argumentDefaultDelim):]
+		result.hasDefault = true
+		result.name = result.name[:delimPos]
+	}
+
+	return result
+}
+
+func isEscapable(resolvers *argumentResolvers, definition string) bool {
+	if strings.HasPrefix(definition, argumentOptionalMarker) || strings.HasPrefix(definition, argumentEscape) {
+		return true
+	}
+	_, resolver := resolvers.match(definition)
+
+	return nil != resolver
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+// factoryArgument is one argument of factory method call
+type factoryArgument struct {
+	// Position in factory method call
+	position     int
+	argumentType reflect.Type
+	// Position in Factory.Arguments, -1 if argument has no definition (service is resolved by type)
+	definitionNum int
+}
+
+// Returns arguments of factory method call starting from firstArgument, Factory.Arguments describe arguments
+// starting from firstArgument. Definitions following last argument of variadic factory method describe elements
+// of variadic argument, variadic argument without definitions is empty.
+func factoryCallArguments(factory *Factory, firstArgument int) []factoryArgument {
+	factoryMethodType := reflect.TypeOf(factory.Create)
+	fixedCount := factoryMethodType.NumIn()
+	if factoryMethodType.IsVariadic() {
+		fixedCount--
+	}
+
+	result := make([]factoryArgument, 0, factoryMethodType.NumIn())
+	for position := firstArgument; position < fixedCount; position++ {
+		definitionNum := position - firstArgument
+		if definitionNum >= len(factory.Arguments) {
+			definitionNum = -1
+		}
+		result = append(
+			result,
+			factoryArgument{position: position, argumentType: factoryMethodType.In(position), definitionNum: definitionNum},
+		)
+	}
+
+	if factoryMethodType.IsVariadic() {
+		elementType := factoryMethodType.In(fixedCount).Elem()
+		for position := fixedCount; position-firstArgument < len(factory.Arguments); position++ {
+			if position < firstArgument {
+				continue
+			}
+			result = append(
+				result,
+				factoryArgument{position: position, argumentType: elementType, definitionNum: position - firstArgument},
+			)
+		}
+	}
+
+	return result
+}
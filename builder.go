@@ -0,0 +1,313 @@
+package gioc
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const sealedMessage = "Container is sealed, registrations are not allowed"
+
+// BuildError is returned by ContainerBuilder.Build, it contains all problems found during build
+type BuildError struct {
+	Errors []error
+}
+
+func (e *BuildError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, buildError := range e.Errors {
+		messages = append(messages, buildError.Error())
+	}
+
+	return fmt.Sprintf("Container build failed with %d errors:\n", len(e.Errors)) + strings.Join(messages, "\n")
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+// ContainerBuilder collects registrations, parameters and settings, Build() compiles them into sealed Container.
+// Registration methods work like methods of Container with same names, but problems which Container reports with
+// return values (like AddServiceAlias for not registered service) are collected and returned by Build().
+// Builder passed to Module.Register adds namespace of module to aliases (see Module).
+// Builder is not changed by Build(), so it can build several Containers.
+type ContainerBuilder struct {
+	container      *Container
+	errors         []error
+	compilerPasses []CompilerPass
+	// Prefix of aliases of module being installed, like "metrics.", empty for application builder
+	namespace string
+	// Factories and decorators registered by module, their arguments are checked by checkModuleReferences
+	moduleFactories []interface{}
+	// Builder of Container.Install stages registrations: they are made on copy of Container and remembered,
+	// Container is changed only if all modules are installed without errors
+	staging bool
+	staged  []func(c *Container)
+}
+
+func (b *ContainerBuilder) RegisterServiceFactoryByAlias(serviceAlias string, factory interface{}, enableCaching bool) *ContainerBuilder {
+	serviceAlias = b.alias(serviceAlias)
+	b.addModuleFactory(factory)
+	b.apply(func(c *Container) { c.RegisterServiceFactoryByAlias(serviceAlias, factory, enableCaching) })
+
+	return b
+}
+
+func (b *ContainerBuilder) RegisterServiceFactoryByObject(serviceObj interface{}, factory interface{}, enableCaching bool) *ContainerBuilder {
+	b.addModuleFactory(factory)
+	b.apply(func(c *Container) { c.RegisterServiceFactoryByObject(serviceObj, factory, enableCaching) })
+
+	return b
+}
+
+func (b *ContainerBuilder) RegisterInstance(serviceAlias string, serviceObj interface{}) *ContainerBuilder {
+	serviceAlias = b.alias(serviceAlias)
+	b.apply(func(c *Container) { c.RegisterInstance(serviceAlias, serviceObj) })
+
+	return b
+}
+
+func (b *ContainerBuilder) RegisterInstanceByObject(serviceObj interface{}) *ContainerBuilder {
+	b.apply(func(c *Container) { c.RegisterInstanceByObject(serviceObj) })
+
+	return b
+}
+
+func (b *ContainerBuilder) AddServiceAlias(existingAlias, newAlias string) *ContainerBuilder {
+	existingAlias, newAlias = b.alias(existingAlias), b.alias(newAlias)
+	isAdded := false
+	b.apply(func(c *Container) { isAdded = c.AddServiceAlias(existingAlias, newAlias) })
+	if !isAdded {
+		b.errors = append(
+			b.errors,
+			fmt.Errorf("alias '%s' can not be added: service '%s' not registered", newAlias, existingAlias),
+		)
+	}
+
+	return b
+}
+
+func (b *ContainerBuilder) AddServiceAliasByObject(serviceObj interface{}, newAlias string) *ContainerBuilder {
+	newAlias = b.alias(newAlias)
+	isAdded := false
+	b.apply(func(c *Container) { isAdded = c.AddServiceAliasByObject(serviceObj, newAlias) })
+	if !isAdded {
+		b.errors = append(
+			b.errors,
+			fmt.Errorf(
+				"alias '%s' can not be added: service with type %s not registered",
+				newAlias,
+				reflect.TypeOf(serviceObj).String(),
+			),
+		)
+	}
+
+	return b
+}
+
+func (b *ContainerBuilder) Decorate(aliasOrType interface{}, decorator interface{}) *ContainerBuilder {
+	aliasOrType = b.aliasOrType(aliasOrType)
+	b.addModuleFactory(decorator)
+	b.apply(func(c *Container) { c.Decorate(aliasOrType, decorator) })
+
+	return b
+}
+
+// Labels service registered with alias (string) or type (object, see RegisterServiceFactoryByObject) with tags,
+// compiler passes find services by tags (see Definitions.Tagged)
+func (b *ContainerBuilder) Tag(aliasOrType interface{}, tags ...string) *ContainerBuilder {
+	aliasOrType = b.aliasOrType(aliasOrType)
+	entry, subject := b.container.readAliasOrType(aliasOrType)
+	if nil == entry {
+		b.errors = append(b.errors, fmt.Errorf("%s can not be tagged: service not registered", subject))
+
+		return b
+	}
+	b.apply(func(c *Container) {
+		entry, _ := c.readAliasOrType(aliasOrType)
+		c.registry.addTags(entry, tags)
+	})
+
+	return b
+}
+
+func (b *ContainerBuilder) MarkPrivate(aliasOrType interface{}) *ContainerBuilder {
+	aliasOrType = b.aliasOrType(aliasOrType)
+	entry, subject := b.container.readAliasOrType(aliasOrType)
+	if nil == entry {
+		b.errors = append(b.errors, fmt.Errorf("%s can not be marked private: service not registered", subject))
+
+		return b
+	}
+	b.apply(func(c *Container) {
+		entry, _ := c.readAliasOrType(aliasOrType)
+		c.registry.setPrivate(entry, true)
+	})
+
+	return b
+}
+
+// Adds compiler pass, passes are run by Build() in order they were added, before Container is validated
+func (b *ContainerBuilder) AddCompilerPass(pass CompilerPass) *ContainerBuilder {
+	b.compilerPasses = append(b.compilerPasses, pass)
+
+	return b
+}
+
+// Parameters values can reference other parameters with ${name}, references are resolved by Build()
+func (b *ContainerBuilder) SetParameters(parameters map[string]string) *ContainerBuilder {
+	b.apply(func(c *Container) { c.SetParameters(parameters) })
+
+	return b
+}
+
+func (b *ContainerBuilder) SetSecretParameters(parameters map[string]string) *ContainerBuilder {
+	b.apply(func(c *Container) { c.SetSecretParameters(parameters) })
+
+	return b
+}
+
+func (b *ContainerBuilder) LoadParameters(source ParametersSource) *ContainerBuilder {
+	var loadError error
+	b.apply(func(c *Container) { loadError = c.LoadParameters(source) })
+	if nil != loadError {
+		b.errors = append(b.errors, loadError)
+	}
+
+	return b
+}
+
+func (b *ContainerBuilder) LoadSecretParameters(source ParametersSource) *ContainerBuilder {
+	var loadError error
+	b.apply(func(c *Container) { loadError = c.LoadSecretParameters(source) })
+	if nil != loadError {
+		b.errors = append(b.errors, loadError)
+	}
+
+	return b
+}
+
+func (b *ContainerBuilder) RegisterArgumentResolver(prefix string, resolver ArgumentResolver) *ContainerBuilder {
+	b.apply(func(c *Container) { c.RegisterArgumentResolver(prefix, resolver) })
+
+	return b
+}
+
+func (b *ContainerBuilder) RegisterTypeConverter(typeObj reflect.Type, converter TypeConverter) *ContainerBuilder {
+	b.apply(func(c *Container) { c.RegisterTypeConverter(typeObj, converter) })
+
+	return b
+}
+
+func (b *ContainerBuilder) SetStrictMode(enabled bool) *ContainerBuilder {
+	b.apply(func(c *Container) { c.SetStrictMode(enabled) })
+
+	return b
+}
+
+func (b *ContainerBuilder) SetRebuildOnParametersChange(enabled bool) *ContainerBuilder {
+	b.apply(func(c *Container) { c.SetRebuildOnParametersChange(enabled) })
+
+	return b
+}
+
+func (b *ContainerBuilder) AddObserver(observer Observer) *ContainerBuilder {
+	b.apply(func(c *Container) { c.AddObserver(observer) })
+
+	return b
+}
+
+func (b *ContainerBuilder) EnableTracing() *ContainerBuilder {
+	b.apply(func(c *Container) { c.EnableTracing() })
+
+	return b
+}
+
+// Compiles registrations into sealed Container:
+// 1. Runs compiler passes on definitions of services, first failed pass stops the build
+// 2. Resolves ${name} references in parameters values
+// 3. Validates registrations and checks dependency cycles (see Container.Validate)
+// 4. Parses argument definitions of every factory and decorator once, so they are not parsed on every instantiation
+// Returns *BuildError with all found problems. Returned Container rejects registrations (registration methods
+// panic), parameters still can be changed and reloaded, but references in new values are not resolved.
+func (b *ContainerBuilder) Build() (*Container, error) {
+	buildErrors := append(make([]error, 0), b.errors...)
+
+	c := b.container.Clone()
+	if len(b.compilerPasses) > 0 {
+		buildErrors = append(buildErrors, b.compile(c)...)
+	}
+	buildErrors = append(buildErrors, c.parameters.interpolate()...)
+	buildErrors = append(buildErrors, c.Validate()...)
+	if len(buildErrors) > 0 {
+		c.Close()
+
+		return nil, &BuildError{Errors: buildErrors}
+	}
+
+	for _, entry := range c.registry.entries() {
+		entry.plans = make(map[*Factory][]*argumentDefinition, 1+len(entry.decorators))
+		for _, factory := range append([]*Factory{entry.factory}, entry.decorators...) {
+			plan := make([]*argumentDefinition, 0, len(factory.Arguments))
+			for _, definition := range factory.Arguments {
+				plan = append(plan, parseArgumentDefinition(c.resolvers, definition))
+			}
+			entry.plans[factory] = plan
+		}
+	}
+	c.sealed = true
+
+	return c, nil
+}
+
+// Makes registration on Container of builder, remembers it if builder stages registrations
+func (b *ContainerBuilder) apply(registration func(c *Container)) {
+	registration(b.container)
+	if b.staging {
+		b.staged = append(b.staged, registration)
+	}
+}
+
+// Returns alias with namespace of module being installed. Inside module alias starting with "/" is absolute,
+// it is returned without "/" and namespace (see Module).
+func (b *ContainerBuilder) alias(alias string) string {
+	if "" == b.namespace {
+		return alias
+	}
+	if strings.HasPrefix(alias, absoluteAliasPrefix) {
+		return alias[len(absoluteAliasPrefix):]
+	}
+
+	return b.namespace + alias
+}
+
+func (b *ContainerBuilder) aliasOrType(aliasOrType interface{}) interface{} {
+	if alias, isAlias := aliasOrType.(string); isAlias {
+		return b.alias(alias)
+	}
+
+	return aliasOrType
+}
+
+// Runs compiler passes and replaces registry of Container with changed definitions
+func (b *ContainerBuilder) compile(c *Container) []error {
+	definitions := newDefinitions(c.registry)
+	for _, pass := range b.compilerPasses {
+		if passError := pass(definitions); nil != passError {
+			return []error{fmt.Errorf("compiler pass %s failed: %w", functionName(pass), passError)}
+		}
+	}
+
+	compiledRegistry, registryErrors := definitions.registry(c.registry.servicesCounter)
+	c.registry = compiledRegistry
+
+	return registryErrors
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+func NewContainerBuilder() *ContainerBuilder {
+	return &ContainerBuilder{
+		container:      newContainer(),
+		errors:         make([]error, 0),
+		compilerPasses: make([]CompilerPass, 0),
+	}
+}
@@ -0,0 +1,123 @@
+package gioc
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Returns entry registered with alias in this Container or, if alias is not registered here, in parent Containers
+// (see NewChildContainer), and Container entry is registered in. Returns nil entry if alias is not registered.
+func (c *Container) lookupAlias(alias string) (*registryEntry, *Container) {
+	for owner := c; nil != owner; owner = owner.parent {
+		if entry := owner.registry.readAlias(alias); nil != entry {
+			return entry, owner
+		}
+	}
+
+	return nil, c
+}
+
+// Same as lookupAlias, but for services registered by type
+func (c *Container) lookupType(serviceType reflect.Type) (*registryEntry, *Container) {
+	for owner := c; nil != owner; owner = owner.parent {
+		if entry := owner.registry.readType(serviceType); nil != entry {
+			return entry, owner
+		}
+	}
+
+	return nil, c
+}
+
+// Returns parameters of Container parameter is set in, parameters of this Container if parameter is not set
+func (c *Container) lookupParameters(key string) *parametersBag {
+	for owner := c; nil != owner; owner = owner.parent {
+		if owner.parameters.IsSet(key) {
+			return owner.parameters
+		}
+	}
+
+	return c.parameters
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Parameters of child Container together with parameters of its parents
+type childParameters struct {
+	container *Container
+}
+
+func (p *childParameters) GetString(key string) string {
+	return p.container.lookupParameters(key).GetString(key)
+}
+
+func (p *childParameters) IsSet(key string) bool {
+	return p.container.lookupParameters(key).IsSet(key)
+}
+
+func (p *childParameters) IsSecret(key string) bool {
+	return p.container.lookupParameters(key).IsSecret(key)
+}
+
+func (p *childParameters) Keys() []string {
+	unique := make(map[string]bool)
+	result := make([]string, 0)
+	for owner := p.container; nil != owner; owner = owner.parent {
+		for _, key := range owner.parameters.Keys() {
+			if !unique[key] {
+				unique[key] = true
+				result = append(result, key)
+			}
+		}
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func (c *Container) addChild(child *Container) {
+	c.childrenMutex.Lock()
+	defer c.childrenMutex.Unlock()
+
+	c.children[child] = true
+}
+
+func (c *Container) removeChild(child *Container) {
+	c.childrenMutex.Lock()
+	defer c.childrenMutex.Unlock()
+
+	delete(c.children, child)
+}
+
+func (c *Container) getChildren() []*Container {
+	c.childrenMutex.Lock()
+	defer c.childrenMutex.Unlock()
+
+	result := make([]*Container, 0, len(c.children))
+	for child := range c.children {
+		result = append(result, child)
+	}
+
+	return result
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Creates Container which falls back to parent for services and parameters it does not have, so per-plugin or
+// per-tenant Containers can share infrastructure of parent. Child can override services and parameters of parent
+// by registering them with same alias, type or name.
+// Services of parent are built by parent: their dependencies are resolved in parent, ignoring overrides of child,
+// and their instances are shared by all children. Child gets copies of parent's argument resolvers and type
+// converters and its strict mode setting. Closing child does not close parent.
+// Parameters reloaded by parent are reloaded for child too (unless child overrides them): Reloadable services of child
+// are notified, with SetRebuildOnParametersChange enabled on child its services consuming changed parameters or
+// depending on rebuilt services of parent are rebuilt. Child is not notified after it is closed.
+func NewChildContainer(parent *Container) *Container {
+	child := NewContainer()
+	child.parent = parent
+	child.resolvers = parent.resolvers.clone()
+	child.converters = parent.converters.clone()
+	child.strictMode = parent.strictMode
+	parent.addChild(child)
+
+	return child
+}
@@ -1,30 +1,73 @@
 package gioc
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"strings"
+	"sync"
+	"time"
 )
 
 type Container struct {
-	registry      *registry
-	parameters    *parametersBag
-	taskManager   *taskManager
+	registry    *registry
+	parameters  *parametersBag
+	resolvers   *argumentResolvers
+	converters  *typeConverters
+	taskManager *taskManager
+	resolutions *resolutionTracker
+	strictMode  bool
+
+	// Cycles are checked incrementally on every registration. cyclesChecked is false if full check is required,
+	// cycle is first detected and not fixed cycle, cycleEntry - service it goes through.
+	cyclesMutex   sync.Mutex
 	cyclesChecked bool
+	cycle         Cycle
+	cycleEntry    *registryEntry
+
+	watchersMutex             sync.Mutex
+	watchers                  []*ParametersWatcher
+	rebuildOnParametersChange bool
+
+	observersMutex sync.RWMutex
+	observers      observersList
+	tracer         *traceRecorder
+
+	// Container services and parameters are looked up in if they are not registered here (see NewChildContainer)
+	parent *Container
+	// Child Containers are notified about reloaded parameters of parent
+	childrenMutex sync.Mutex
+	children      map[*Container]bool
+
+	// Names of installed modules (see Install)
+	modulesMutex sync.Mutex
+	modules      map[string]bool
+
+	// Sealed Container is built by ContainerBuilder: it is validated, so cycles are not checked on every get,
+	// and it rejects registrations
+	sealed bool
 }
 
 // Registers service factory to Container. Parameter factory must be one of two types:
 // 1. Factory method (function). Function with one out parameter - pointer to new instance of service
 // 2. Instance of Factory struct, where Create attribute is proper factory method (see p.1)
 func (c *Container) RegisterServiceFactoryByAlias(serviceAlias string, factory interface{}, enableCaching bool) *Container {
+	c.panicOnSealed()
+	c.panicOnDuplicate(c.registry.readAlias(serviceAlias), "Service '"+serviceAlias+"'")
 	factoryObj := createFactoryFromInterface(factory)
-	c.registry.writeAlias(
-		serviceAlias,
-		&registryEntry{
-			factory:        factoryObj,
-			cachingEnabled: enableCaching,
-			cachedService:  nil,
-		},
+	entry := &registryEntry{
+		factory:        factoryObj,
+		cachingEnabled: enableCaching,
+		cachedService:  nil,
+	}
+	previous := c.registry.writeAlias(serviceAlias, entry)
+	checkRegistrationCycles(
+		c,
+		entry,
+		func() { c.registry.restoreAlias(serviceAlias, previous) },
+		"service '"+serviceAlias+"'",
 	)
 
 	return c
@@ -34,14 +77,21 @@ func (c *Container) RegisterServiceFactoryByAlias(serviceAlias string, factory i
 // 1. Factory method (function). Function with one out parameter - pointer to new instance of service
 // 2. Instance of Factory struct, where Create attribute is proper factory method (see p.1)
 func (c *Container) RegisterServiceFactoryByObject(serviceObj interface{}, factory interface{}, enableCaching bool) *Container {
+	c.panicOnSealed()
+	serviceType := reflect.TypeOf(serviceObj)
+	c.panicOnDuplicate(c.registry.readType(serviceType), "Service with type "+serviceType.String())
 	factoryObj := createFactoryFromInterface(factory)
-	c.registry.writeType(
-		reflect.TypeOf(serviceObj),
-		&registryEntry{
-			factory:        factoryObj,
-			cachingEnabled: enableCaching,
-			cachedService:  nil,
-		},
+	entry := &registryEntry{
+		factory:        factoryObj,
+		cachingEnabled: enableCaching,
+		cachedService:  nil,
+	}
+	previous := c.registry.writeType(serviceType, entry)
+	checkRegistrationCycles(
+		c,
+		entry,
+		func() { c.registry.restoreType(serviceType, previous) },
+		"service with type "+serviceType.String(),
 	)
 
 	return c
@@ -49,7 +99,7 @@ func (c *Container) RegisterServiceFactoryByObject(serviceObj interface{}, facto
 
 func (c *Container) AddServiceAlias(existingAlias, newAlias string) bool {
 	if serviceEntry := c.registry.readAlias(existingAlias); nil != serviceEntry {
-		c.registry.writeAlias(newAlias, serviceEntry)
+		c.addAlias(newAlias, serviceEntry)
 
 		return true
 	}
@@ -60,7 +110,7 @@ func (c *Container) AddServiceAlias(existingAlias, newAlias string) bool {
 func (c *Container) AddServiceAliasByObject(serviceObj interface{}, newAlias string) bool {
 	serviceType := reflect.TypeOf(serviceObj)
 	if serviceEntry := c.registry.readType(serviceType); nil != serviceEntry {
-		c.registry.writeAlias(newAlias, serviceEntry)
+		c.addAlias(newAlias, serviceEntry)
 
 		return true
 	}
@@ -68,34 +118,270 @@ func (c *Container) AddServiceAliasByObject(serviceObj interface{}, newAlias str
 	return false
 }
 
+func (c *Container) addAlias(alias string, entry *registryEntry) {
+	c.panicOnSealed()
+	if existing := c.registry.readAlias(alias); existing != entry {
+		c.panicOnDuplicate(existing, "Service '"+alias+"'")
+	}
+	previous := c.registry.writeAlias(alias, entry)
+	checkRegistrationCycles(
+		c,
+		entry,
+		func() { c.registry.restoreAlias(alias, previous) },
+		"alias '"+alias+"'",
+	)
+}
+
+// In strict mode registrations creating dependency cycles and registrations with already registered alias or type
+// are rejected with panic (use Replace to change factory of registered service).
+// In non-strict mode cycles are detected at registration too, but panic is thrown only on attempt to get service,
+// registration with already registered alias or type overwrites it.
+func (c *Container) SetStrictMode(enabled bool) *Container {
+	c.strictMode = enabled
+
+	return c
+}
+
+// Returns true if Container was built by ContainerBuilder and rejects registrations
+func (c *Container) IsSealed() bool {
+	return c.sealed
+}
+
+func (c *Container) panicOnSealed() {
+	if c.sealed {
+		panic(sealedMessage)
+	}
+}
+
+func (c *Container) panicOnDuplicate(existing *registryEntry, subject string) {
+	if c.strictMode && nil != existing {
+		panic(subject + " is already registered. Use Replace to change its factory")
+	}
+}
+
+// Replaces factory of service registered with alias (string) or type (object, see RegisterServiceFactoryByObject).
+// Service is updated in place, so every alias and type of the service gets new factory, caching flag and decorators
+// are kept. Factory must return service of type returned by previous factory.
+// Cached instances of the service and of all services depending on it are dropped.
+// Returns previous factory, so it can be restored by another Replace call.
+func (c *Container) Replace(aliasOrType interface{}, factory interface{}) Factory {
+	c.panicOnSealed()
+	entry, subject := c.readAliasOrType(aliasOrType)
+	if nil == entry {
+		panic(fmt.Sprintf("Failed to replace %s. Service not registered", subject))
+	}
+
+	factoryObj := createFactoryFromInterface(factory)
+	serviceType := reflect.TypeOf(entry.factory.Create).Out(0)
+	if !reflect.TypeOf(factoryObj.Create).Out(0).AssignableTo(serviceType) {
+		panic("Factory must return service of type " + serviceType.String())
+	}
+
+	c.dropFromCache([]*registryEntry{entry})
+	previous, previousIsInstance := c.registry.replaceFactory(entry, factoryObj, false)
+	checkRegistrationCycles(
+		c,
+		entry,
+		func() { c.registry.replaceFactory(entry, previous, previousIsInstance) },
+		subject,
+	)
+
+	return *previous
+}
+
+// Removes alias (string) or type (object, see RegisterServiceFactoryByObject) of service. Service itself is
+// removed when its last alias or type is removed. Returns error and keeps registration if other services require it,
+// optional dependencies do not prevent removal.
+func (c *Container) Remove(aliasOrType interface{}) error {
+	if c.sealed {
+		return errors.New(sealedMessage)
+	}
+
+	entry, subject := c.readAliasOrType(aliasOrType)
+	if nil == entry {
+		return errors.New(fmt.Sprintf("Failed to remove %s. Service not registered", subject))
+	}
+
+	alias, isAlias := aliasOrType.(string)
+	serviceType := reflect.TypeOf(aliasOrType)
+	dependents := make([]string, 0)
+	aliases, types := c.registry.keys()
+	for _, dependent := range c.registry.entries() {
+		for _, dependency := range c.entryDependencies(dependent) {
+			if dependency.Optional {
+				continue
+			}
+			if (isAlias && AliasDependency == dependency.Kind && alias == dependency.Alias) ||
+				(!isAlias && TypeDependency == dependency.Kind && serviceType == dependency.Type) {
+				dependents = append(dependents, entryName(aliases[dependent], types[dependent]))
+				break
+			}
+		}
+	}
+	if len(dependents) > 0 {
+		return errors.New(
+			fmt.Sprintf("Failed to remove %s. Services depending on it: %s", subject, strings.Join(dependents, ", ")),
+		)
+	}
+
+	if isAlias {
+		c.registry.restoreAlias(alias, nil)
+	} else {
+		c.registry.restoreType(serviceType, nil)
+	}
+
+	// Removed service could be part of detected cycle
+	c.cyclesMutex.Lock()
+	c.cyclesChecked = false
+	c.cyclesMutex.Unlock()
+
+	return nil
+}
+
+// Puts ready-made serviceObj to cache of service registered with existingAlias, so it is returned instead of
+// instance created by factory. Panics if type of serviceObj does not match type returned by service factory.
+// Returns false if existingAlias is not registered.
 func (c *Container) BindObjectToAlias(existingAlias string, serviceObj interface{}) bool {
+	c.panicOnSealed()
+
 	var serviceEntry *registryEntry
 
 	if serviceEntry = c.registry.readAlias(existingAlias); nil == serviceEntry {
 		return false
 	}
 
+	if !isInstanceOfEntry(serviceObj, serviceEntry) {
+		panic("serviceObj passed to function is not same type as service with alias " + existingAlias)
+	}
+
+	c.registry.addServiceToCache(existingAlias, serviceObj)
+
+	return true
+}
+
+// Registers ready-made service instance (for example logger or config built before Container) with alias.
+// Instance must be a pointer. If alias is already registered, type of instance must match type returned by
+// registered factory.
+func (c *Container) RegisterInstance(serviceAlias string, serviceObj interface{}) *Container {
+	c.panicOnSealed()
+	c.panicOnDuplicate(c.registry.readAlias(serviceAlias), "Service '"+serviceAlias+"'")
+	subject := "service '" + serviceAlias + "'"
+	entry := newInstanceEntry(serviceObj, c.registry.readAlias(serviceAlias), subject)
+	previous := c.registry.writeAlias(serviceAlias, entry)
+	checkRegistrationCycles(
+		c,
+		entry,
+		func() { c.registry.restoreAlias(serviceAlias, previous) },
+		subject,
+	)
+
+	return c
+}
+
+// Registers ready-made service instance by its type, so it is injected into factory arguments of that type.
+// Instance must be a pointer.
+func (c *Container) RegisterInstanceByObject(serviceObj interface{}) *Container {
+	c.panicOnSealed()
 	serviceType := reflect.TypeOf(serviceObj)
+	if nil == serviceType {
+		panic("Instance must be pointer")
+	}
+	c.panicOnDuplicate(c.registry.readType(serviceType), "Service with type "+serviceType.String())
+	subject := "service with type " + serviceType.String()
+	entry := newInstanceEntry(serviceObj, c.registry.readType(serviceType), subject)
+	previous := c.registry.writeType(serviceType, entry)
+	checkRegistrationCycles(
+		c,
+		entry,
+		func() { c.registry.restoreType(serviceType, previous) },
+		subject,
+	)
 
-	factoryMethodType := reflect.TypeOf(serviceEntry.factory.Create)
-	if serviceType != factoryMethodType.Out(0) {
-		panic("serviceObj passed to function is not same type as service with alias " + existingAlias)
+	return c
+}
+
+// Wraps service registered with alias (string) or type (object, see RegisterServiceFactoryByObject) with decorator.
+// Decorator must be one of two types:
+// 1. Function, which first argument is decorated service and out parameter is service of same type
+// 2. Instance of Factory struct, where Create attribute is proper decorator function (see p.1).
+// Factory.Arguments describe decorator arguments following decorated service.
+// Decorators are applied in order they were added: first one wraps service created by factory, next one wraps
+// result of previous one. Decorator applies to service, so it is seen by every alias and type of the service.
+// Cached instance of service is dropped, services already holding it are not rebuilt.
+func (c *Container) Decorate(aliasOrType interface{}, decorator interface{}) *Container {
+	c.panicOnSealed()
+	entry, subject := c.readAliasOrType(aliasOrType)
+	if nil == entry {
+		panic(fmt.Sprintf("Failed to decorate %s. Service not registered", subject))
 	}
 
-	return false
+	decoratorObj := createFactoryFromInterface(decorator)
+	checkDecoratorMethod(decoratorObj.Create, reflect.TypeOf(entry.factory.Create).Out(0))
+
+	c.registry.addDecorator(entry, decoratorObj)
+	checkRegistrationCycles(
+		c,
+		entry,
+		func() { c.registry.removeLastDecorator(entry) },
+		"decorator of "+subject,
+	)
+
+	return c
 }
 
+// Marks service registered with alias (string) or type (object, see RegisterServiceFactoryByObject) as private:
+// it can be injected into factory arguments and got from Locator injected into factory, but GetByAlias, GetByObject
+// and Locator created by Container.Locator refuse to return it.
+// Private flag applies to service, so it affects every alias and type of the service.
+func (c *Container) MarkPrivate(aliasOrType interface{}) *Container {
+	c.panicOnSealed()
+	entry, subject := c.readAliasOrType(aliasOrType)
+	if nil == entry {
+		panic(fmt.Sprintf("Failed to mark %s private. Service not registered", subject))
+	}
+	c.registry.setPrivate(entry, true)
+
+	return c
+}
+
+// Returns Locator of services with aliases. Locator gets services lazily, on Locator.Get call, so aliases do not have
+// to be registered at the moment Locator is created. Private services are not returned, as by GetByAlias.
+func (c *Container) Locator(aliases ...string) *Locator {
+	return newLocator(c, aliases, false)
+}
+
+// Returns entry registered with alias (string) or type of object and description of it for messages
+func (c *Container) readAliasOrType(aliasOrType interface{}) (*registryEntry, string) {
+	if alias, isAlias := aliasOrType.(string); isAlias {
+		return c.registry.readAlias(alias), "service '" + alias + "'"
+	}
+
+	serviceType := reflect.TypeOf(aliasOrType)
+
+	return c.registry.readType(serviceType), "service with type " + serviceType.String()
+}
+
+// Returns service registered with alias. Private services (see MarkPrivate) are not returned, panics for them.
 func (c *Container) GetByAlias(alias string) interface{} {
-	if !c.cyclesChecked {
-		if noCycles, cycledService := c.CheckCycles(); !noCycles {
-			panic("Circular dependencies detected: " + cycledService)
-		}
+	if registryEntry, _ := c.lookupAlias(alias); nil != registryEntry && registryEntry.private {
+		panic(fmt.Sprintf("Failed to get service '%s'. Service is private, it can only be injected as dependency", alias))
 	}
 
-	registryEntry := c.registry.readAlias(alias)
+	return c.getByAlias(alias)
+}
+
+// Same as GetByAlias, but private services are returned too
+func (c *Container) getByAlias(alias string) interface{} {
+	c.panicOnCycles()
+
+	registryEntry, owner := c.lookupAlias(alias)
 	if nil == registryEntry {
 		panic(fmt.Sprintf("Failed to instantiate service '%s'. Factory for service not registered", alias))
 	}
+	// Service of parent is built in parent's context
+	if owner != c {
+		return owner.getByAlias(alias)
+	}
 
 	service, serviceError := c.getByRegistryEntry(registryEntry)
 	if nil != serviceError {
@@ -105,26 +391,60 @@ func (c *Container) GetByAlias(alias string) interface{} {
 	return service
 }
 
+// Returns service registered by type of serviceObj. Private services (see MarkPrivate) are not returned, panics
+// for them.
 func (c *Container) GetByObject(serviceObj interface{}) interface{} {
-	if !c.cyclesChecked {
-		if noCycles, cycledService := c.CheckCycles(); !noCycles {
-			panic("Circular dependencies detected: " + cycledService)
-		}
-	}
+	c.panicOnCycles()
 
 	serviceType := reflect.TypeOf(serviceObj)
+	if registryEntry, _ := c.lookupType(serviceType); nil != registryEntry && registryEntry.private {
+		panic(
+			fmt.Sprintf(
+				"Failed to get service with type '%s'. Service is private, it can only be injected as dependency",
+				serviceType.String(),
+			),
+		)
+	}
 
 	return c.getByReflectType(serviceType)
 }
 
+func (c *Container) panicOnCycles() {
+	// Services of child depend on services of parent, so cycle in parent breaks child too
+	if nil != c.parent {
+		c.parent.panicOnCycles()
+	}
+
+	// Sealed Container was checked for cycles on build and can not be changed
+	if c.sealed {
+		return
+	}
+
+	c.cyclesMutex.Lock()
+	cyclesChecked, cycle := c.cyclesChecked, c.cycle
+	c.cyclesMutex.Unlock()
+
+	if !cyclesChecked {
+		if noCycles, cycledService := c.CheckCycles(); !noCycles {
+			panic("Circular dependencies detected: " + cycledService)
+		}
+	} else if nil != cycle {
+		panic("Circular dependencies detected: " + cycle.String())
+	}
+}
+
 func (c *Container) getByReflectType(serviceType reflect.Type) interface{} {
-	registryEntry := c.registry.readType(serviceType)
+	registryEntry, owner := c.lookupType(serviceType)
 	if nil == registryEntry {
 		serviceTypeName := serviceType.String()
 		panic(
 			fmt.Sprintf("Failed to instantiate service with type '%s'. Factory for service type %s not registered", serviceTypeName, serviceTypeName),
 		)
 	}
+	// Service of parent is built in parent's context
+	if owner != c {
+		return owner.getByReflectType(serviceType)
+	}
 
 	service, serviceError := c.getByRegistryEntry(registryEntry)
 	if nil != serviceError {
@@ -137,83 +457,355 @@ func (c *Container) getByReflectType(serviceType reflect.Type) interface{} {
 }
 
 func (c *Container) getByRegistryEntry(entry *registryEntry) (interface{}, error) {
-	if nil != entry.cachedService {
-		return entry.cachedService, nil
+	observers, event := c.serviceEvent(entry)
+
+	if cachedService, _ := entry.cached(); nil != cachedService {
+		observers.CacheHit(event)
+
+		return cachedService, nil
 	}
 
-	serviceCreationListener := make(chan *taskResult)
+	observers.ResolveStart(event)
+	started := time.Now()
+	service, generation, serviceError := c.buildRegistryEntry(entry)
+	event.Duration, event.Error = time.Since(started), serviceError
+	observers.ResolveEnd(event)
+
+	// Cache was dropped while service was built (see Container.ReloadParameters), instance may hold old
+	// dependencies or parameters, so service is built again
+	if _, currentGeneration := entry.cached(); nil == serviceError && entry.cachingEnabled && generation != currentGeneration {
+		return c.getByRegistryEntry(entry)
+	}
+
+	return service, serviceError
+}
+
+// Returns built service and generation of cache build started at (see registryEntry.cached)
+func (c *Container) buildRegistryEntry(entry *registryEntry) (interface{}, int, error) {
+	// Factory may get services from Container inside Create, cycles created this way are detected only here.
+	// Without this check task of service would wait for itself forever.
+	waiter, loop := c.resolutions.startWaiting(entry)
+	if nil != loop {
+		path := make(Cycle, 0, len(loop))
+		for _, loopEntry := range loop {
+			path = append(path, c.registry.name(loopEntry))
+		}
+
+		return nil, 0, errors.New("Dependency cycle detected during resolution: " + path.String())
+	}
+	if nil != waiter {
+		defer c.resolutions.stopWaiting(waiter)
+	}
+
+	serviceCreationListener := make(chan *taskResult, 1)
 	c.taskManager.addTask(&taskDefinition{
 		taskName: fmt.Sprintf("service_%d", entry.id),
 		listener: serviceCreationListener,
 		perform: func() (interface{}, error) {
-			return c.instantiate(entry.factory)
+			goroutineId := c.resolutions.startBuilding(entry)
+			defer c.resolutions.stopBuilding(goroutineId)
+
+			// Instance is cached by task, so requests joining task started before cache was dropped do not cache
+			// stale instance
+			_, generation := entry.cached()
+			started := time.Now()
+			defer func() {
+				entry.recordBuild(time.Since(started))
+			}()
+
+			service, instantiationError := c.instantiate(entry)
+			if nil != instantiationError {
+				return nil, instantiationError
+			}
+			if entry.cachingEnabled {
+				entry.cacheBuilt(service, generation)
+			}
+
+			return &builtService{service: service, generation: generation}, nil
 		},
 	})
 
 	instantiationResult := <-serviceCreationListener
 	if nil != instantiationResult.taskError {
-		return nil, instantiationResult.taskError
+		return nil, 0, instantiationResult.taskError
 	}
-	service := instantiationResult.result
+	built := instantiationResult.result.(*builtService)
+
+	return built.service, built.generation, nil
+}
 
-	if entry.cachingEnabled {
-		entry.cachedService = service
+// Result of service building task
+type builtService struct {
+	service    interface{}
+	generation int
+}
+
+func (c *Container) instantiate(entry *registryEntry) (interface{}, error) {
+	factoryInputArguments, argumentsError := c.resolveFactoryArguments(entry.factory, 0, entry.plans[entry.factory])
+	if nil != argumentsError {
+		return nil, argumentsError
+	}
+
+	observers, event := c.serviceEvent(entry)
+	observers.FactoryStart(event)
+	started := time.Now()
+	service, callError := callFactory(reflect.ValueOf(entry.factory.Create), factoryInputArguments)
+	event.Duration, event.Error = time.Since(started), callError
+	observers.FactoryEnd(event)
+	if nil != callError {
+		return nil, callError
+	}
+
+	// First decorator wraps service created by factory, every next one wraps result of previous one
+	for _, decorator := range entry.decorators {
+		decoratorInputArguments, argumentsError := c.resolveFactoryArguments(decorator, 1, entry.plans[decorator])
+		if nil != argumentsError {
+			return nil, argumentsError
+		}
+		decoratorInputArguments[0] = reflect.ValueOf(service)
+
+		if service, callError = callFactory(reflect.ValueOf(decorator.Create), decoratorInputArguments); nil != callError {
+			return nil, callError
+		}
 	}
 
 	return service, nil
 }
 
-func (c *Container) instantiate(factory *Factory) (interface{}, error) {
-	factoryMethodValue := reflect.ValueOf(factory.Create)
-
-	factoryMethodType := factoryMethodValue.Type()
-	factoryInputArguments := make([]reflect.Value, factoryMethodType.NumIn())
-	for argumentNum := 0; argumentNum < factoryMethodType.NumIn(); argumentNum++ {
-		var argument interface{}
-
-		argumentType := factoryMethodType.In(argumentNum)
-
-		// If there is argument data for current argument - process it
-		if argumentNum < len(factory.Arguments) {
-			argumentDefinition := factory.Arguments[argumentNum]
-			argumentDefinitionLen := len(argumentDefinition)
-
-			if argumentDefinitionLen >= 1 && "@" == argumentDefinition[:1] {
-				// Sign @ indicates that it is service alias
-				argument = c.GetByAlias(argumentDefinition[1:])
-			} else if argumentDefinitionLen >= 1 && "#" == argumentDefinition[:1] {
-				// Sign # indicates that it is container parameter
-				parameter := argumentDefinition[1:]
-				if !c.parameters.IsSet(parameter) {
-					return nil, errors.New(fmt.Sprintf("Container's parameter '%s' not found in Container's parameters bag", parameter))
-				}
-				argument = getArgumentValueFromString(argumentType.Kind(), c.parameters.GetString(parameter))
-			} else {
-				argument = getArgumentValueFromString(argumentType.Kind(), argumentDefinition)
-			}
+// Resolves factory method arguments starting from firstArgument, previous arguments are left for caller.
+// Factory.Arguments describe arguments starting from firstArgument. Plan contains parsed Factory.Arguments,
+// definitions are parsed on every call if plan is nil (see ContainerBuilder.Build).
+func (c *Container) resolveFactoryArguments(factory *Factory, firstArgument int, plan []*argumentDefinition) ([]reflect.Value, error) {
+	callArguments := factoryCallArguments(factory, firstArgument)
+	factoryInputArguments := make([]reflect.Value, firstArgument+len(callArguments))
+	for _, argument := range callArguments {
+		// If there is no data for current argument - just get it from Container
+		if argument.definitionNum < 0 {
+			factoryInputArguments[argument.position] = reflect.ValueOf(c.getByReflectType(argument.argumentType))
+			continue
+		}
+
+		var definition *argumentDefinition
+		if nil != plan {
+			definition = plan[argument.definitionNum]
+		} else {
+			definition = parseArgumentDefinition(c.resolvers, factory.Arguments[argument.definitionNum])
+		}
+		argumentValue, argumentError := c.resolveArgument(definition, argument.argumentType)
+		if nil != argumentError {
+			return nil, argumentError
+		}
+		factoryInputArguments[argument.position] = argumentValue
+	}
+
+	return factoryInputArguments, nil
+}
+
+func (c *Container) resolveArgument(definition *argumentDefinition, argumentType reflect.Type) (reflect.Value, error) {
+	if definition.isLiteral() {
+		return c.convertArgument(argumentType, definition.name, false)
+	}
+
+	argument, resolveError := definition.resolver.Resolve(c, definition.name, argumentType)
+	if argumentPrefixParameter == definition.prefix {
+		c.getObservers().ParameterLookup(
+			ParameterEvent{
+				Parameter: definition.name,
+				Found:     ErrArgumentNotFound != resolveError,
+				Secret:    c.lookupParameters(definition.name).IsSecret(definition.name),
+			},
+		)
+	}
+	if ErrArgumentNotFound == resolveError {
+		if definition.hasDefault {
+			argument, resolveError = definition.defaultValue, nil
+		} else if definition.optional {
+			return reflect.Zero(argumentType), nil
+		} else if argumentPrefixParameter == definition.prefix {
+			return reflect.Value{}, errors.New(
+				fmt.Sprintf("Container's parameter '%s' not found in Container's parameters bag", definition.name),
+			)
 		} else {
-			// If there is no data for current argument - just get it from Container
-			argument = c.getByReflectType(argumentType)
+			return reflect.Value{}, errors.New(
+				fmt.Sprintf("Value for argument '%s%s' not found", definition.prefix, definition.name),
+			)
 		}
+	}
+	if nil != resolveError {
+		return reflect.Value{}, resolveError
+	}
 
-		factoryInputArguments[argumentNum] = reflect.ValueOf(argument)
+	if nil == argument {
+		return reflect.Zero(argumentType), nil
 	}
+	if stringArgument, isString := argument.(string); isString && !reflect.TypeOf(argument).AssignableTo(argumentType) {
+		isSecret := false
+		if secretResolver, canBeSecret := definition.resolver.(SecretArgumentResolver); canBeSecret {
+			isSecret = secretResolver.IsSecret(c, definition.name)
+		}
 
-	service := factoryMethodValue.Call(factoryInputArguments)[0].Interface()
+		return c.convertArgument(argumentType, stringArgument, isSecret)
+	}
 
-	return service, nil
+	return reflect.ValueOf(argument), nil
+}
+
+// For secret values conversion error contains neither the value nor the cause (causes like strconv.NumError
+// include the value too)
+func (c *Container) convertArgument(argumentType reflect.Type, stringValue string, isSecret bool) (reflect.Value, error) {
+	argument, conversionError := c.converters.convert(argumentType, stringValue)
+	if nil != conversionError {
+		if isSecret {
+			return reflect.Value{}, newSecretConversionError(argumentType)
+		}
+
+		return reflect.Value{}, newConversionError(argumentType, stringValue, conversionError)
+	}
+
+	return argument, nil
+}
+
+// Returns dependencies created by factory arguments. Dependencies are returned in order of factory arguments.
+func (c *Container) factoryDependencies(factory *Factory) []Dependency {
+	return c.factoryArgumentsDependencies(factory, 0)
+}
+
+// Returns dependencies created by arguments of service factory and then by arguments of decorators
+func (c *Container) entryDependencies(entry *registryEntry) []Dependency {
+	dependencies := c.factoryDependencies(entry.factory)
+	for decoratorNum, decorator := range entry.decorators {
+		for _, dependency := range c.factoryArgumentsDependencies(decorator, 1) {
+			dependency.Decorator = decoratorNum + 1
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	return dependencies
+}
+
+// Same as factoryDependencies, but arguments before firstArgument are skipped (see resolveFactoryArguments)
+func (c *Container) factoryArgumentsDependencies(factory *Factory, firstArgument int) []Dependency {
+	dependencies := make([]Dependency, 0)
+
+	for _, argument := range factoryCallArguments(factory, firstArgument) {
+		// If there is no argument data for current parameter - it is a service registered by type
+		if argument.definitionNum < 0 {
+			dependencies = append(
+				dependencies,
+				Dependency{Kind: TypeDependency, Type: argument.argumentType, Argument: argument.position},
+			)
+			continue
+		}
+
+		argumentDefinition := parseArgumentDefinition(c.resolvers, factory.Arguments[argument.definitionNum])
+		if argumentDefinition.isLiteral() {
+			continue
+		}
+
+		for _, dependency := range argumentDefinition.resolver.Dependencies(c, argumentDefinition.name) {
+			dependency.Argument = argument.position
+			dependency.Optional = dependency.Optional || argumentDefinition.optional || argumentDefinition.hasDefault
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	return dependencies
 }
 
 // Checks all registered services for dependency cycles.
 // First returning parameter is flag showing cycle presence - true - no cycles, false - cycles detected
-// Second returning parameter contains name of service with detected dependency cycle. If no cycles detected it is empty string
+// Second returning parameter contains path of first detected dependency cycle. If no cycles detected it is empty string
 func (c *Container) CheckCycles() (bool, string) {
-	noCycles, cycledService := checkCyclesForContainer(c)
-	if noCycles {
-		c.cyclesChecked = true
+	if nil != c.parent {
+		if noCycles, cycledService := c.parent.CheckCycles(); !noCycles {
+			return false, cycledService
+		}
+	}
+
+	cycles := updateCyclesStateForContainer(c)
+	if 0 == len(cycles) {
+		return true, ""
 	}
 
-	return noCycles, cycledService
+	return false, cycles[0].String()
+}
+
+// Returns all dependency cycles, one for every group of services depending on each other.
+// Result is the same on every run: cycles are ordered by registration order of services.
+func (c *Container) FindCycles() []Cycle {
+	if nil != c.parent {
+		return append(c.parent.FindCycles(), checkCyclesForContainer(c)...)
+	}
+
+	return checkCyclesForContainer(c)
+}
+
+// Checks all registrations without instantiating services and returns all found problems (see ValidationError):
+// dependencies on not registered services, not set parameters, literal arguments and parameters which
+// can not be converted to factory argument type, excess argument definitions and dependency cycles.
+// Returns empty slice if no problems found.
+func (c *Container) Validate() []error {
+	return validateContainer(c)
+}
+
+// Registers converter used to cast literal arguments and parameters to typeObj.
+// Converter registered for a type has priority over built-in conversion rules.
+func (c *Container) RegisterTypeConverter(typeObj reflect.Type, converter TypeConverter) *Container {
+	c.panicOnSealed()
+	c.converters.register(typeObj, converter)
+
+	return c
+}
+
+// Registers resolver for argument definitions starting with prefix (for example "$" for environment variables
+// or "file:" for secret files). Prefixes "@" and "#" are used by Container for services and parameters,
+// but can be overridden too.
+func (c *Container) RegisterArgumentResolver(prefix string, resolver ArgumentResolver) *Container {
+	c.panicOnSealed()
+	if "" == prefix || argumentOptionalMarker == prefix || argumentEscape == prefix {
+		panic("Invalid argument resolver prefix '" + prefix + "'")
+	}
+
+	c.resolvers.register(prefix, resolver)
+	// Resolver can change dependencies of already registered services
+	c.cyclesMutex.Lock()
+	c.cyclesChecked = false
+	c.cyclesMutex.Unlock()
+
+	return c
+}
+
+// Returns snapshot of registered services and dependencies between them.
+// Graph can be exported with WriteDOT, WriteMermaid and WriteJSON methods.
+func (c *Container) Graph() *Graph {
+	return buildGraph(c)
+}
+
+// Instantiates all services with enabled caching. Service starts building as soon as all its dependencies are built,
+// so independent subtrees are built in parallel. At most workers services are built at once (runtime.NumCPU() if
+// workers <= 0).
+// Returns *WarmupError containing errors of all services which failed to build, services depending on them are not
+// built. Cancellation of ctx stops building, already started services are finished.
+func (c *Container) Warmup(ctx context.Context, workers int) error {
+	return warmupContainer(ctx, c, workers)
+}
+
+// Returns descriptors of all registered services ordered by id (registration order)
+func (c *Container) Services() []ServiceDescriptor {
+	return describeContainer(c)
+}
+
+// Returns descriptor of service alias points at, services of parent Container (see NewChildContainer) are described
+// too. Second returned value is false if alias is not registered.
+func (c *Container) Describe(alias string) (ServiceDescriptor, bool) {
+	entry, owner := c.lookupAlias(alias)
+	if nil == entry {
+		return ServiceDescriptor{}, false
+	}
+
+	aliases, types := owner.registry.keys()
+
+	return describeEntry(entry, aliases[entry], types[entry]), true
 }
 
 func (c *Container) SetParameters(parameters map[string]string) {
@@ -222,24 +814,367 @@ func (c *Container) SetParameters(parameters map[string]string) {
 	}
 }
 
+// Sets parameters which values must never be shown in error messages, dumps and exports
+func (c *Container) SetSecretParameters(parameters map[string]string) {
+	for key, val := range parameters {
+		c.parameters.setSecret(key, val)
+	}
+}
+
+// Loads parameters from source (see ParametersSource)
+func (c *Container) LoadParameters(source ParametersSource) error {
+	parameters, sourceError := source.Parameters()
+	if nil != sourceError {
+		return sourceError
+	}
+
+	c.parameters.update(parameters, false, trackedSource(source))
+
+	return nil
+}
+
+// Loads parameters from source and marks them as secret (see SetSecretParameters)
+func (c *Container) LoadSecretParameters(source ParametersSource) error {
+	parameters, sourceError := source.Parameters()
+	if nil != sourceError {
+		return sourceError
+	}
+
+	c.parameters.update(parameters, true, trackedSource(source))
+
+	return nil
+}
+
+// Reloads parameters from source. Parameters loaded from the same source earlier (by LoadParameters or reload) and
+// missing in source now are unset and reported as changed, other parameters missing in source are kept as is.
+// After reload cached services implementing Reloadable are notified about changed parameters.
+// Returns names of changed parameters.
+func (c *Container) ReloadParameters(source ParametersSource) ([]string, error) {
+	return c.reloadParameters(source, false)
+}
+
+// Same as ReloadParameters, but loaded parameters are marked as secret
+func (c *Container) ReloadSecretParameters(source ParametersSource) ([]string, error) {
+	return c.reloadParameters(source, true)
+}
+
+// Starts reloading parameters from source every time trigger fires (see FilePollTrigger and SignalTrigger)
+func (c *Container) WatchParameters(source ParametersSource, trigger ReloadTrigger) *ParametersWatcher {
+	return c.watchParameters(source, trigger, false)
+}
+
+// Same as WatchParameters, but loaded parameters are marked as secret
+func (c *Container) WatchSecretParameters(source ParametersSource, trigger ReloadTrigger) *ParametersWatcher {
+	return c.watchParameters(source, trigger, true)
+}
+
+// If enabled, cached services whose factories consumed changed parameters (#param arguments) are dropped from cache
+// on parameters reload, together with cached services depending on them. So they are rebuilt on next request.
+func (c *Container) SetRebuildOnParametersChange(enabled bool) *Container {
+	c.rebuildOnParametersChange = enabled
+
+	return c
+}
+
+func (c *Container) watchParameters(source ParametersSource, trigger ReloadTrigger, secret bool) *ParametersWatcher {
+	watcher := newParametersWatcher(c, source, trigger, secret)
+
+	c.watchersMutex.Lock()
+	c.watchers = append(c.watchers, watcher)
+	c.watchersMutex.Unlock()
+
+	watcher.watch()
+
+	return watcher
+}
+
+func (c *Container) reloadParameters(source ParametersSource, secret bool) ([]string, error) {
+	parameters, sourceError := source.Parameters()
+	if nil != sourceError {
+		return nil, sourceError
+	}
+
+	changed := c.parameters.update(parameters, secret, trackedSource(source))
+	if 0 == len(changed) {
+		return changed, nil
+	}
+
+	c.parametersChanged(changed, make(map[*registryEntry]bool))
+
+	return changed, nil
+}
+
+// Rebuilds and notifies services affected by changed parameters, then passes changes to child Containers.
+// droppedByParents are services of parent Containers dropped from cache, services depending on them hold old instances.
+func (c *Container) parametersChanged(changed []string, droppedByParents map[*registryEntry]bool) {
+	dropped := droppedByParents
+	if c.rebuildOnParametersChange {
+		dropped = c.dropParametersConsumersFromCache(changed, droppedByParents)
+	}
+
+	if len(changed) > 0 {
+		for _, entry := range c.registry.entries() {
+			cachedService, _ := entry.cached()
+			if reloadableService, isReloadable := cachedService.(Reloadable); isReloadable {
+				reloadableService.OnParametersChanged(changed)
+			}
+		}
+	}
+
+	for _, child := range c.getChildren() {
+		// Parameters overridden by child are not changed for it
+		visible := make([]string, 0, len(changed))
+		for _, parameter := range changed {
+			if !child.parameters.IsSet(parameter) {
+				visible = append(visible, parameter)
+			}
+		}
+		if len(visible) > 0 || len(dropped) > 0 {
+			child.parametersChanged(visible, dropped)
+		}
+	}
+}
+
+// Drops consumers of changed parameters and services depending on dropped services of parent Containers.
+// Returns all dropped services, parents' ones included.
+func (c *Container) dropParametersConsumersFromCache(
+	changedParameters []string,
+	droppedByParents map[*registryEntry]bool,
+) map[*registryEntry]bool {
+	isChanged := make(map[string]bool, len(changedParameters))
+	for _, parameter := range changedParameters {
+		isChanged[parameter] = true
+	}
+
+	consumers := make([]*registryEntry, 0)
+	for _, entry := range c.registry.entries() {
+		for _, dependency := range c.entryDependencies(entry) {
+			if ParameterDependency == dependency.Kind && isChanged[dependency.Parameter] {
+				consumers = append(consumers, entry)
+				break
+			}
+			if dependencyEntry, owner := c.lookupDependency(dependency); owner != c && droppedByParents[dependencyEntry] &&
+				!dependency.Lazy {
+				consumers = append(consumers, entry)
+				break
+			}
+		}
+	}
+
+	dropped := c.dropFromCache(consumers)
+	for entry := range droppedByParents {
+		dropped[entry] = true
+	}
+
+	return dropped
+}
+
+// Drops cached instances of entries and of all services depending on them (directly or transitively),
+// because they hold old instances. Returns dropped entries.
+func (c *Container) dropFromCache(entries []*registryEntry) map[*registryEntry]bool {
+	dependents := make(map[*registryEntry][]*registryEntry)
+	for _, entry := range c.registry.entries() {
+		for _, dependency := range c.entryDependencies(entry) {
+			// Services got lazily are not held by dependent
+			if dependency.Lazy {
+				continue
+			}
+			if dependencyEntry := c.dependencyEntry(dependency); nil != dependencyEntry {
+				dependents[dependencyEntry] = append(dependents[dependencyEntry], entry)
+			}
+		}
+	}
+
+	dropped := make(map[*registryEntry]bool)
+	dropQueue := append(make([]*registryEntry, 0, len(entries)), entries...)
+	for len(dropQueue) > 0 {
+		entry := dropQueue[0]
+		dropQueue = dropQueue[1:]
+		if dropped[entry] {
+			continue
+		}
+
+		dropped[entry] = true
+		entry.dropCached()
+		dropQueue = append(dropQueue, dependents[entry]...)
+	}
+
+	return dropped
+}
+
+// Returns registry entry dependency points to, nil for parameters, not registered services and services of parent
+// Container (see NewChildContainer)
+func (c *Container) dependencyEntry(dependency Dependency) *registryEntry {
+	switch dependency.Kind {
+	case TypeDependency:
+		return c.registry.readType(dependency.Type)
+	case AliasDependency:
+		return c.registry.readAlias(dependency.Alias)
+	}
+
+	return nil
+}
+
+// Same as dependencyEntry, but services of parent Container are returned too, with Container they belong to
+func (c *Container) lookupDependency(dependency Dependency) (*registryEntry, *Container) {
+	switch dependency.Kind {
+	case TypeDependency:
+		return c.lookupType(dependency.Type)
+	case AliasDependency:
+		return c.lookupAlias(dependency.Alias)
+	}
+
+	return nil, c
+}
+
+// Adds observer of services resolution events
+func (c *Container) AddObserver(observer Observer) *Container {
+	c.observersMutex.Lock()
+	defer c.observersMutex.Unlock()
+
+	c.addObserver(observer)
+
+	return c
+}
+
+// List is copied, so lists returned by getObservers() are not changed
+func (c *Container) addObserver(observer Observer) {
+	observers := make(observersList, 0, len(c.observers)+1)
+	c.observers = append(append(observers, c.observers...), observer)
+}
+
+// Starts recording of services construction timeline, see WriteTrace
+func (c *Container) EnableTracing() *Container {
+	c.observersMutex.Lock()
+	defer c.observersMutex.Unlock()
+
+	if nil == c.tracer {
+		c.tracer = newTraceRecorder()
+		c.addObserver(c.tracer)
+	}
+
+	return c
+}
+
+// Writes timeline of services construction recorded since EnableTracing() in Chrome trace event format
+// (can be opened in chrome://tracing or Perfetto). Every goroutine is shown as a separate thread: "resolve" events
+// show how long goroutine waited for service, "factory" events show factory calls.
+func (c *Container) WriteTrace(w io.Writer) error {
+	c.observersMutex.RLock()
+	tracer := c.tracer
+	c.observersMutex.RUnlock()
+
+	if nil == tracer {
+		return errors.New("tracing is not enabled, call EnableTracing() before services resolution")
+	}
+
+	return tracer.write(w)
+}
+
+func (c *Container) getObservers() observersList {
+	c.observersMutex.RLock()
+	defer c.observersMutex.RUnlock()
+
+	return c.observers
+}
+
+// Returns observers and event for entry. Name of entry is not computed if there are no observers.
+func (c *Container) serviceEvent(entry *registryEntry) (observersList, ServiceEvent) {
+	observers := c.getObservers()
+	if 0 == len(observers) {
+		return nil, ServiceEvent{}
+	}
+
+	return observers, ServiceEvent{ServiceID: entry.id, Service: c.registry.name(entry)}
+}
+
+// Returns new Container with definitions of this one: services (aliases of one service stay one service),
+// caching flags, decorators, installed modules, parameters (including secrets), argument resolvers, type converters, observers and
+// strict mode, rebuild on parameters change and tracing settings. Instances of services are not copied, every
+// service is built by clone again (ready-made instances registered with RegisterInstance are shared).
+// Parameters watchers are not copied, they keep updating this Container only.
+// Registrations and parameters of clone can be changed without affecting this Container and vice versa.
+// Clone of sealed Container is not sealed.
+func (c *Container) Clone() *Container {
+	clone := NewContainer()
+	clone.registry = c.registry.clone()
+	clone.parameters = c.parameters.clone()
+	clone.resolvers = c.resolvers.clone()
+	clone.converters = c.converters.clone()
+	clone.strictMode = c.strictMode
+	clone.rebuildOnParametersChange = c.rebuildOnParametersChange
+	clone.cyclesChecked = false
+	clone.parent = c.parent
+	if nil != clone.parent {
+		clone.parent.addChild(clone)
+	}
+
+	c.modulesMutex.Lock()
+	for name := range c.modules {
+		clone.modules[name] = true
+	}
+	c.modulesMutex.Unlock()
+
+	c.observersMutex.RLock()
+	defer c.observersMutex.RUnlock()
+	for _, observer := range c.observers {
+		// Every Container records its own timeline
+		if observer != Observer(c.tracer) {
+			clone.addObserver(observer)
+		}
+	}
+	if nil != c.tracer {
+		clone.EnableTracing()
+	}
+
+	return clone
+}
+
+// Returns parameters of Container, parameters of child Container include parameters of parents
 func (c *Container) Parameters() ParametersAccessor {
+	if nil != c.parent {
+		return &childParameters{container: c}
+	}
+
 	return c.parameters
 }
 
 func (c *Container) Close() {
+	c.watchersMutex.Lock()
+	for _, watcher := range c.watchers {
+		watcher.Stop()
+	}
+	c.watchers = nil
+	c.watchersMutex.Unlock()
+
 	c.taskManager.stopServe()
+
+	if nil != c.parent {
+		c.parent.removeChild(c)
+	}
 }
 
 // ---------------------------------------------------------------------------------------------------------------------
 
 func NewContainer() *Container {
-	c := Container{
+	c := newContainer()
+	c.taskManager.serve()
+
+	return c
+}
+
+// Creates Container without running task manager, such Container can not build services
+// (used by ContainerBuilder to collect registrations)
+func newContainer() *Container {
+	return &Container{
 		registry:      newRegistry(),
 		parameters:    newParametersBag(),
+		resolvers:     newArgumentResolvers(),
+		converters:    newTypeConverters(),
 		taskManager:   newTaskManager(),
-		cyclesChecked: false,
+		resolutions:   newResolutionTracker(),
+		cyclesChecked: true,
+		modules:       make(map[string]bool),
+		children:      make(map[*Container]bool),
 	}
-	c.taskManager.serve()
-
-	return &c
 }
@@ -0,0 +1,197 @@
+package gioc
+
+import (
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TypeConverter converts string value (literal argument or parameter) to value of type it was registered for
+type TypeConverter func(value string) (interface{}, error)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+func builtinTypeConverters() map[reflect.Type]TypeConverter {
+	return map[reflect.Type]TypeConverter{
+		reflect.TypeOf(time.Duration(0)): func(value string) (interface{}, error) {
+			return time.ParseDuration(value)
+		},
+		reflect.TypeOf(time.Time{}): func(value string) (interface{}, error) {
+			if timeVal, parseError := time.Parse(time.RFC3339Nano, value); nil == parseError {
+				return timeVal, nil
+			}
+
+			return time.Parse("2006-01-02", value)
+		},
+		reflect.TypeOf((*url.URL)(nil)): func(value string) (interface{}, error) {
+			return url.Parse(value)
+		},
+		reflect.TypeOf(net.IP{}): func(value string) (interface{}, error) {
+			ip := net.ParseIP(value)
+			if nil == ip {
+				return nil, errors.New("invalid IP address")
+			}
+
+			return ip, nil
+		},
+		reflect.TypeOf([]string{}): func(value string) (interface{}, error) {
+			return parseCsv(value)
+		},
+		reflect.TypeOf(map[string]string{}): func(value string) (interface{}, error) {
+			result := make(map[string]string)
+
+			// JSON object or comma separated list of key=value pairs
+			if strings.HasPrefix(strings.TrimSpace(value), "{") {
+				unmarshalError := json.Unmarshal([]byte(value), &result)
+
+				return result, unmarshalError
+			}
+
+			pairs, csvError := parseCsv(value)
+			if nil != csvError {
+				return nil, csvError
+			}
+			for _, pair := range pairs {
+				delimPos := strings.Index(pair, "=")
+				if delimPos < 0 {
+					return nil, errors.New("map entry is not a key=value pair")
+				}
+				result[strings.TrimSpace(pair[:delimPos])] = strings.TrimSpace(pair[delimPos+1:])
+			}
+
+			return result, nil
+		},
+	}
+}
+
+func parseCsv(value string) ([]string, error) {
+	if "" == strings.TrimSpace(value) {
+		return []string{}, nil
+	}
+
+	reader := csv.NewReader(strings.NewReader(value))
+	reader.TrimLeadingSpace = true
+
+	return reader.Read()
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+type typeConverters struct {
+	mutex      sync.RWMutex
+	converters map[reflect.Type]TypeConverter
+}
+
+func (tc *typeConverters) register(typeObj reflect.Type, converter TypeConverter) {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+
+	tc.converters[typeObj] = converter
+}
+
+func (tc *typeConverters) read(typeObj reflect.Type) TypeConverter {
+	tc.mutex.RLock()
+	defer tc.mutex.RUnlock()
+
+	return tc.converters[typeObj]
+}
+
+// Converts string value to value of type typeObj. Conversion rules are applied in next order:
+// 1. Converter registered for exactly that type
+// 2. String value as is, if it can be assigned to typeObj (string, interface{})
+// 3. encoding.TextUnmarshaler implementation
+// 4. Conversion by kind for basic types (so named types like "type Port int" are supported)
+// 5. JSON decoding for structs, maps, slices and pointers to them
+func (tc *typeConverters) convert(typeObj reflect.Type, stringValue string) (reflect.Value, error) {
+	if converter := tc.read(typeObj); nil != converter {
+		converted, conversionError := converter(stringValue)
+		if nil != conversionError {
+			return reflect.Value{}, conversionError
+		}
+
+		convertedValue := reflect.ValueOf(converted)
+		if !convertedValue.IsValid() {
+			return reflect.Zero(typeObj), nil
+		} else if !convertedValue.Type().AssignableTo(typeObj) {
+			return reflect.Value{}, errors.New("converter returned value of type " + convertedValue.Type().String())
+		}
+
+		return convertedValue, nil
+	}
+
+	if reflect.TypeOf(stringValue).AssignableTo(typeObj) {
+		return reflect.ValueOf(stringValue), nil
+	}
+
+	if typeObj.Kind() == reflect.Ptr && typeObj.Implements(textUnmarshalerType) {
+		result := reflect.New(typeObj.Elem())
+		if unmarshalError := result.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(stringValue)); nil != unmarshalError {
+			return reflect.Value{}, unmarshalError
+		}
+
+		return result, nil
+	}
+	if reflect.PtrTo(typeObj).Implements(textUnmarshalerType) {
+		result := reflect.New(typeObj)
+		if unmarshalError := result.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(stringValue)); nil != unmarshalError {
+			return reflect.Value{}, unmarshalError
+		}
+
+		return result.Elem(), nil
+	}
+
+	switch typeObj.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Ptr:
+		result := reflect.New(typeObj)
+		if unmarshalError := json.Unmarshal([]byte(stringValue), result.Interface()); nil != unmarshalError {
+			return reflect.Value{}, unmarshalError
+		}
+
+		return result.Elem(), nil
+	}
+
+	converted, conversionError := getArgumentValueFromString(typeObj.Kind(), stringValue)
+	if nil != conversionError {
+		return reflect.Value{}, conversionError
+	}
+
+	return reflect.ValueOf(converted).Convert(typeObj), nil
+}
+
+func (tc *typeConverters) clone() *typeConverters {
+	tc.mutex.RLock()
+	defer tc.mutex.RUnlock()
+
+	clone := &typeConverters{
+		converters: make(map[reflect.Type]TypeConverter, len(tc.converters)),
+	}
+	for typeObj, converter := range tc.converters {
+		clone.converters[typeObj] = converter
+	}
+
+	return clone
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+func newTypeConverters() *typeConverters {
+	return &typeConverters{
+		converters: builtinTypeConverters(),
+	}
+}
+
+func newConversionError(typeObj reflect.Type, stringValue string, err error) error {
+	return errors.New(fmt.Sprintf("Failed to convert '%s' to %s: %s", stringValue, typeObj.String(), err.Error()))
+}
+
+func newSecretConversionError(typeObj reflect.Type) error {
+	return errors.New(fmt.Sprintf("Failed to convert '%s' to %s", RedactedValue, typeObj.String()))
+}
@@ -1,184 +1,319 @@
 package gioc
 
 import (
-	"container/list"
-	"errors"
 	"fmt"
-	"reflect"
+	"sort"
+	"strings"
 )
 
+// Cycle is a dependency cycle: names of services along the cycle, first service is repeated at the end
+// (like "A->B->C->A").
+type Cycle []string
+
+func (c Cycle) String() string {
+	return strings.Join(c, "->")
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
 type checkerNode struct {
-	id int
+	id              int
 	serviceName     string
 	dependenciesIds []int
+
+	// Tarjan's algorithm bookkeeping
+	index   int
+	lowLink int
+	onStack bool
 }
 
 // ---------------------------------------------------------------------------------------------------------------------
 
-type dependencyChain struct {
-	*list.List
+type checkerTable struct {
+	nodes map[int]*checkerNode
+	// Node ids in ascending order, to get same result on every run
+	order []int
 }
 
-func (c *dependencyChain) Contains(registryElementId int) bool {
-	for e := c.Front(); nil != e; e = e.Next() {
-		if e.Value.(*checkerNode).id == registryElementId {
-			return true
+// Finds strongly connected components with Tarjan's algorithm, O(V+E). Every component with more than one node
+// or with a node depending on itself contains at least one cycle.
+func (t *checkerTable) stronglyConnectedComponents() [][]*checkerNode {
+	components := make([][]*checkerNode, 0)
+	stack := make([]*checkerNode, 0)
+	nextIndex := 1
+
+	var strongConnect func(node *checkerNode)
+	strongConnect = func(node *checkerNode) {
+		node.index = nextIndex
+		node.lowLink = nextIndex
+		nextIndex++
+		stack = append(stack, node)
+		node.onStack = true
+
+		for _, dependencyId := range node.dependenciesIds {
+			dependencyNode := t.nodes[dependencyId]
+			if 0 == dependencyNode.index {
+				strongConnect(dependencyNode)
+				if dependencyNode.lowLink < node.lowLink {
+					node.lowLink = dependencyNode.lowLink
+				}
+			} else if dependencyNode.onStack && dependencyNode.index < node.lowLink {
+				node.lowLink = dependencyNode.index
+			}
 		}
-	}
-
-	return false
-}
 
-func (c *dependencyChain) String() string {
-	var result string
+		if node.lowLink != node.index {
+			return
+		}
 
-	for e := c.Front(); nil != e; e = e.Next() {
-		if "" != result {
-			result += "->"
+		component := make([]*checkerNode, 0)
+		for {
+			member := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			member.onStack = false
+			component = append(component, member)
+			if member == node {
+				break
+			}
 		}
+		components = append(components, component)
+	}
 
-		result += e.Value.(*checkerNode).serviceName
+	for _, id := range t.order {
+		if 0 == t.nodes[id].index {
+			strongConnect(t.nodes[id])
+		}
 	}
 
-	return result
+	return components
 }
 
-func (c *dependencyChain) Copy() *dependencyChain {
-	result := newDependencyChain()
+// Returns shortest cycle through start node, walking only nodes of the component. Breadth-first search, O(V+E)
+// of the component.
+func (t *checkerTable) cycleInComponent(start *checkerNode, component map[int]bool) Cycle {
+	previous := map[int]int{start.id: 0}
+	queue := []*checkerNode{start}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for _, dependencyId := range node.dependenciesIds {
+			if dependencyId == start.id {
+				// Restoring path start->...->node->start
+				path := []string{start.serviceName}
+				for id := node.id; id != start.id; id = previous[id] {
+					path = append(path, t.nodes[id].serviceName)
+				}
+				path = append(path, start.serviceName)
+				// Path was collected from the end
+				for left, right := 1, len(path)-2; left < right; left, right = left+1, right-1 {
+					path[left], path[right] = path[right], path[left]
+				}
+
+				return path
+			}
 
-	for e := c.Front(); e != nil; e = e.Next() {
-		result.PushBack(e.Value)
+			if _, isVisited := previous[dependencyId]; !isVisited && component[dependencyId] {
+				previous[dependencyId] = node.id
+				queue = append(queue, t.nodes[dependencyId])
+			}
+		}
 	}
 
-	return result
+	return nil
 }
 
-func newDependencyChain() *dependencyChain {
-	return &dependencyChain{List: list.New()}
+// Returns one cycle for every strongly connected component containing cycles. Cycles are ordered by smallest
+// service id in component, every cycle starts from the service with smallest id.
+func (t *checkerTable) cycles() []Cycle {
+	result, _ := t.cyclesWithStartIds()
+
+	return result
 }
 
-// ---------------------------------------------------------------------------------------------------------------------
+// Same as cycles(), but also returns ids of services cycles start from
+func (t *checkerTable) cyclesWithStartIds() ([]Cycle, []int) {
+	result := make([]Cycle, 0)
+	startIds := make([]int, 0)
+
+	components := t.stronglyConnectedComponents()
+	componentStarts := make([]*checkerNode, 0)
+	componentMembers := make(map[*checkerNode]map[int]bool)
+	for _, component := range components {
+		start := component[0]
+		members := make(map[int]bool, len(component))
+		for _, member := range component {
+			members[member.id] = true
+			if member.id < start.id {
+				start = member
+			}
+		}
 
-type checkerTable map[int]*checkerNode
+		if 1 == len(component) && !start.dependsOn(start.id) {
+			continue
+		}
 
-func (t checkerTable) walkCheckerNode(node *checkerNode, chain *dependencyChain) string {
-	if chain.Contains(node.id) {
-		chain.PushBack(node)
+		componentStarts = append(componentStarts, start)
+		componentMembers[start] = members
+	}
 
-		return chain.String()
+	// Components are found in reverse topological order, sorting them to get order not depending on graph shape
+	sort.Slice(componentStarts, func(i, j int) bool {
+		return componentStarts[i].id < componentStarts[j].id
+	})
+	for _, start := range componentStarts {
+		result = append(result, t.cycleInComponent(start, componentMembers[start]))
+		startIds = append(startIds, start.id)
 	}
 
-	chain.PushBack(node)
-
-	for _, dependencyId := range node.dependenciesIds {
-		dependencyNode := t[dependencyId]
-		// Passing copy of current dependency chain (not current chain itself) to next
-		// walkCheckerNode() call to remove possible interference from "sibling" branches.
-		// Sibling interference can be in case like:
-		// Root->Node1->Node1_1->Leaf
-		// Root->Node2->Node2_1->Node1_1->Leaf
-		if loopedPath := t.walkCheckerNode(dependencyNode, chain.Copy()); "" != loopedPath {
-			return loopedPath
+	return result, startIds
+}
+
+func (n *checkerNode) dependsOn(id int) bool {
+	for _, dependencyId := range n.dependenciesIds {
+		if dependencyId == id {
+			return true
 		}
 	}
 
-	return ""
+	return false
 }
 
 // ---------------------------------------------------------------------------------------------------------------------
 
-func checkCyclesForContainer(c *Container) (bool, string) {
-	var checker = make(checkerTable)
-
-	// Building checker table. Checker table is indexed by registryEntry.id (which is unique for every unique service)
-	// to avoid duplicate checks because of multiple aliases for one service
-
-	for serviceAlias := range c.registry.aliasIndex {
-		registryElement := c.registry.readAlias(serviceAlias)
-		if _, isInChecker := checker[registryElement.id]; !isInChecker {
-			newCheckerNode, checkerNodeError := createCheckerNode(c, registryElement)
-			if nil != checkerNodeError {
-				panic(
-					fmt.Sprintf(
-						"Failed to check dependencies cycles for service '%s'. Error: %s",
-						serviceAlias,
-						checkerNodeError,
-					),
-				)
-			}
-
-			newCheckerNode.serviceName = serviceAlias
-			checker[registryElement.id] = newCheckerNode
-		}
+// Builds checker table from dependency graph. Checker table is indexed by registryEntry.id (which is unique
+// for every unique service) to avoid duplicate checks because of multiple aliases for one service.
+// Parameters, lazy and missing dependencies do not create edges.
+func newCheckerTable(graph *Graph) *checkerTable {
+	table := &checkerTable{
+		nodes: make(map[int]*checkerNode, len(graph.Nodes)),
+		order: make([]int, 0, len(graph.Nodes)),
 	}
 
-	for serviceType := range c.registry.typeIndex {
-		registryElement := c.registry.readType(serviceType)
-		if _, isInChecker := checker[registryElement.id]; !isInChecker {
-			serviceTypeName := serviceType.String()
-
-			newCheckerNode, checkerNodeError := createCheckerNode(c, registryElement)
-			if nil != checkerNodeError {
-				panic(
-					fmt.Sprintf(
-						"Failed to check dependencies cycles for service with type %s. Error: %s",
-						serviceTypeName,
-						checkerNodeError,
-					),
-				)
-			}
-
-			newCheckerNode.serviceName = serviceTypeName
-			checker[registryElement.id] = newCheckerNode
+	// Graph nodes are already ordered by id
+	for _, node := range graph.Nodes {
+		table.nodes[node.ID] = &checkerNode{
+			id:              node.ID,
+			serviceName:     node.Name,
+			dependenciesIds: make([]int, 0),
 		}
+		table.order = append(table.order, node.ID)
 	}
 
-	// Searching cycles
-	for _, currentNode := range checker {
-		loopedPath := checker.walkCheckerNode(currentNode, newDependencyChain())
-		if "" != loopedPath {
-			return false, loopedPath
+	for _, edge := range graph.Edges {
+		if ParameterDependency == edge.Kind || edge.Lazy || 0 == edge.To {
+			continue
 		}
+
+		table.nodes[edge.From].dependenciesIds = append(table.nodes[edge.From].dependenciesIds, edge.To)
 	}
 
-	return true, ""
+	return table
 }
 
-func createCheckerNode(c *Container, registryElement *registryEntry) (*checkerNode, error) {
-	newCheckerNode := &checkerNode{
-		id: registryElement.id,
-		dependenciesIds: make([]int, 0),
+// Missing dependencies are not cycles, they are reported by Container.Validate and on service instantiation
+func checkCyclesForContainer(c *Container) []Cycle {
+	return newCheckerTable(buildGraph(c)).cycles()
+}
+
+// Runs full check and remembers first found cycle, so Container can refuse to return services
+func updateCyclesStateForContainer(c *Container) []Cycle {
+	cycles, startIds := newCheckerTable(buildGraph(c)).cyclesWithStartIds()
+
+	c.cyclesMutex.Lock()
+	defer c.cyclesMutex.Unlock()
+
+	c.cyclesChecked = true
+	c.cycle, c.cycleEntry = nil, nil
+	if len(cycles) > 0 {
+		for _, entry := range c.registry.entries() {
+			if entry.id == startIds[0] {
+				c.cycle, c.cycleEntry = cycles[0], entry
+			}
+		}
 	}
 
-	// Getting ids of dependencies
-	factoryMethodValue := reflect.ValueOf(registryElement.factory.Create)
+	return cycles
+}
 
-	factoryMethodType := factoryMethodValue.Type()
-	for argumentNum := 0; argumentNum < factoryMethodType.NumIn(); argumentNum++ {
-		argumentId := -1
+// Searches shortest cycle going through start entry. Walks only services reachable from start and resolves their
+// dependencies on the fly, so registration of one service does not require walk over the whole graph.
+func findCycleThroughEntry(c *Container, start *registryEntry) Cycle {
+	previous := map[*registryEntry]*registryEntry{start: nil}
+	queue := []*registryEntry{start}
 
-		argumentType := factoryMethodType.In(argumentNum)
+	for len(queue) > 0 {
+		entry := queue[0]
+		queue = queue[1:]
 
-		// If there is argument data for current parameter - process it
-		if argumentNum < len(registryElement.factory.Arguments) {
-			argumentDefinition := registryElement.factory.Arguments[argumentNum]
-			// Sign @ indicates that it is service alias
-			if len(argumentDefinition) >= 1 && "@" == argumentDefinition[:1] {
-				argumentId = c.registry.readAlias(argumentDefinition[1:]).id
+		for _, dependency := range c.entryDependencies(entry) {
+			if dependency.Lazy {
+				continue
 			}
-		} else {
-			// If there is no argument data for current parameter - suppose that it is a service registered by object
-			argumentsRegistryElement := c.registry.readType(argumentType)
-			if nil == argumentsRegistryElement {
-				return nil, errors.New("factory not found")
+			dependencyEntry := c.dependencyEntry(dependency)
+			if nil == dependencyEntry {
+				continue
+			}
+
+			if dependencyEntry == start {
+				aliases, types := c.registry.keys()
+				path := Cycle{entryName(aliases[start], types[start])}
+				for pathEntry := entry; pathEntry != start; pathEntry = previous[pathEntry] {
+					path = append(path, entryName(aliases[pathEntry], types[pathEntry]))
+				}
+				path = append(path, path[0])
+				// Path was collected from the end
+				for left, right := 1, len(path)-2; left < right; left, right = left+1, right-1 {
+					path[left], path[right] = path[right], path[left]
+				}
+
+				return path
+			}
+
+			if _, isVisited := previous[dependencyEntry]; !isVisited {
+				previous[dependencyEntry] = entry
+				queue = append(queue, dependencyEntry)
 			}
-			argumentId = argumentsRegistryElement.id
 		}
+	}
+
+	return nil
+}
 
-		if argumentId >= 0 {
-			newCheckerNode.dependenciesIds = append(newCheckerNode.dependenciesIds, argumentId)
+// Checks cycles created by registration change affecting entry (new service or new alias of existing service).
+// Every new cycle goes through affected entry, so only services reachable from it are checked.
+// In strict mode registration creating cycle is rolled back and panic is thrown.
+func checkRegistrationCycles(c *Container, entry *registryEntry, rollback func(), subject string) {
+	cycle := findCycleThroughEntry(c, entry)
+	if nil != cycle && c.strictMode {
+		rollback()
+		panic(fmt.Sprintf("Registration of %s creates dependency cycle: %s", subject, cycle.String()))
+	}
+
+	c.cyclesMutex.Lock()
+	defer c.cyclesMutex.Unlock()
+
+	if nil != c.cycleEntry && c.cycleEntry != entry {
+		if previousCycle := findCycleThroughEntry(c, c.cycleEntry); nil != previousCycle {
+			// Previously detected cycle still exists, keep reporting it
+			c.cycle = previousCycle
+
+			return
 		}
+
+		// Previously detected cycle was fixed by this registration, but other cycles registered while it
+		// existed were not tracked, so full check is required
+		c.cyclesChecked = false
 	}
 
-	return newCheckerNode, nil
+	if nil != cycle {
+		c.cycle, c.cycleEntry = cycle, entry
+	} else {
+		if nil != c.cycle {
+			// Cycle through this entry was fixed, cycles registered while it existed were not tracked
+			c.cyclesChecked = false
+		}
+		c.cycle, c.cycleEntry = nil, nil
+	}
 }
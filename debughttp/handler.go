@@ -0,0 +1,170 @@
+// Package debughttp serves state of gioc.Container over HTTP, like net/http/pprof does for runtime profiles.
+//
+// Handler is intended for admin port and should be mounted with prefix stripped:
+//
+//	mux.Handle("/debug/gioc/", http.StripPrefix("/debug/gioc", debughttp.NewHandler(container)))
+//
+// Endpoints (all JSON unless stated otherwise):
+//
+//	/            list of endpoints
+//	/services    descriptors of registered services (see gioc.ServiceDescriptor)
+//	/aliases     alias -> service id
+//	/parameters  parameter -> value, values of secret parameters are redacted
+//	/graph       dependency graph
+//	/graph.dot   dependency graph in Graphviz DOT format
+//	/instances   instantiated (cached) services
+//	/timings     duration of last factory run of services which were built at least once
+package debughttp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bassbeaver/gioc"
+)
+
+// Endpoint describes one endpoint served by Handler
+type Endpoint struct {
+	Path        string `json:"path"`
+	Description string `json:"description"`
+}
+
+// Service is a short description of service used by /instances and /timings endpoints
+type Service struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Timing is a duration of last factory run, see gioc.ServiceDescriptor.BuildDuration
+type Timing struct {
+	Service
+	Builds     int   `json:"builds"`
+	DurationNs int64 `json:"duration_ns"`
+}
+
+var endpoints = []Endpoint{
+	{Path: "/services", Description: "registered services"},
+	{Path: "/aliases", Description: "aliases and ids of services they point at"},
+	{Path: "/parameters", Description: "parameters, secrets are redacted"},
+	{Path: "/graph", Description: "dependency graph in JSON format"},
+	{Path: "/graph.dot", Description: "dependency graph in Graphviz DOT format"},
+	{Path: "/instances", Description: "instantiated services"},
+	{Path: "/timings", Description: "construction timings"},
+}
+
+// Handler serves state of gioc.Container. Every request reads current state, nothing is cached.
+type Handler struct {
+	container *gioc.Container
+	mux       *http.ServeMux
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) serveIndex(w http.ResponseWriter, r *http.Request) {
+	if "/" != r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+
+	writeJSON(w, endpoints)
+}
+
+func (h *Handler) serveServices(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, h.container.Services())
+}
+
+func (h *Handler) serveAliases(w http.ResponseWriter, r *http.Request) {
+	aliases := make(map[string]int)
+	for _, descriptor := range h.container.Services() {
+		for _, alias := range descriptor.Aliases {
+			aliases[alias] = descriptor.ID
+		}
+	}
+
+	writeJSON(w, aliases)
+}
+
+func (h *Handler) serveParameters(w http.ResponseWriter, r *http.Request) {
+	accessor := h.container.Parameters()
+	parameters := make(map[string]string)
+	for _, key := range accessor.Keys() {
+		if accessor.IsSecret(key) {
+			parameters[key] = gioc.RedactedValue
+		} else {
+			parameters[key] = accessor.GetString(key)
+		}
+	}
+
+	writeJSON(w, parameters)
+}
+
+func (h *Handler) serveGraph(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	h.container.Graph().WriteJSON(w)
+}
+
+func (h *Handler) serveGraphDOT(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+	h.container.Graph().WriteDOT(w)
+}
+
+func (h *Handler) serveInstances(w http.ResponseWriter, r *http.Request) {
+	instances := make([]Service, 0)
+	for _, descriptor := range h.container.Services() {
+		if descriptor.Instantiated {
+			instances = append(instances, Service{ID: descriptor.ID, Name: descriptor.Name})
+		}
+	}
+
+	writeJSON(w, instances)
+}
+
+func (h *Handler) serveTimings(w http.ResponseWriter, r *http.Request) {
+	timings := make([]Timing, 0)
+	for _, descriptor := range h.container.Services() {
+		if 0 == descriptor.Builds {
+			continue
+		}
+		timings = append(
+			timings,
+			Timing{
+				Service:    Service{ID: descriptor.ID, Name: descriptor.Name},
+				Builds:     descriptor.Builds,
+				DurationNs: descriptor.BuildDuration.Nanoseconds(),
+			},
+		)
+	}
+
+	writeJSON(w, timings)
+}
+
+// Services are ordered by id and maps are encoded with sorted keys, so output is stable
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if encodeError := encoder.Encode(value); nil != encodeError {
+		http.Error(w, encodeError.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+func NewHandler(container *gioc.Container) *Handler {
+	h := &Handler{
+		container: container,
+		mux:       http.NewServeMux(),
+	}
+	h.mux.HandleFunc("/", h.serveIndex)
+	h.mux.HandleFunc("/services", h.serveServices)
+	h.mux.HandleFunc("/aliases", h.serveAliases)
+	h.mux.HandleFunc("/parameters", h.serveParameters)
+	h.mux.HandleFunc("/graph", h.serveGraph)
+	h.mux.HandleFunc("/graph.dot", h.serveGraphDOT)
+	h.mux.HandleFunc("/instances", h.serveInstances)
+	h.mux.HandleFunc("/timings", h.serveTimings)
+
+	return h
+}
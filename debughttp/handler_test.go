@@ -0,0 +1,115 @@
+package debughttp
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bassbeaver/gioc"
+)
+
+type testService1 struct{}
+
+type testService2 struct {
+	S1 *testService1
+}
+
+func newTestContainer() *gioc.Container {
+	c := gioc.NewContainer()
+	c.RegisterServiceFactoryByAlias(
+		"service1",
+		func() *testService1 { return &testService1{} },
+		true,
+	).RegisterServiceFactoryByAlias(
+		"service2",
+		gioc.Factory{
+			Create:    func(s1 *testService1, password string) *testService2 { return &testService2{S1: s1} },
+			Arguments: []string{"@service1", "#db.password"},
+		},
+		true,
+	)
+	c.AddServiceAlias("service1", "default")
+	c.SetParameters(map[string]string{"db.host": "localhost"})
+	c.SetSecretParameters(map[string]string{"db.password": "qwerty"})
+
+	return c
+}
+
+func get(t *testing.T, handler http.Handler, path string) string {
+	server := httptest.NewServer(http.StripPrefix("/debug/gioc", handler))
+	defer server.Close()
+
+	response, requestError := http.Get(server.URL + "/debug/gioc" + path)
+	if nil != requestError {
+		t.Fatalf("Request to %s failed: %s", path, requestError)
+	}
+	defer response.Body.Close()
+	if http.StatusOK != response.StatusCode {
+		t.Fatalf("Request to %s failed with status %d", path, response.StatusCode)
+	}
+
+	body, readError := ioutil.ReadAll(response.Body)
+	if nil != readError {
+		t.Fatalf("Failed to read response of %s: %s", path, readError)
+	}
+
+	return string(body)
+}
+
+func TestHandler(t *testing.T) {
+	c := newTestContainer()
+	defer c.Close()
+	handler := NewHandler(c)
+
+	c.GetByAlias("service2")
+
+	expectedAliases := "{\n  \"default\": 1,\n  \"service1\": 1,\n  \"service2\": 2\n}\n"
+	if aliases := get(t, handler, "/aliases"); expectedAliases != aliases {
+		t.Errorf("Wrong aliases: %s", aliases)
+	}
+
+	expectedParameters := "{\n  \"db.host\": \"localhost\",\n  \"db.password\": \"******\"\n}\n"
+	if parameters := get(t, handler, "/parameters"); expectedParameters != parameters {
+		t.Errorf("Wrong parameters: %s", parameters)
+	}
+
+	expectedInstances := "[\n  {\n    \"id\": 1,\n    \"name\": \"default\"\n  },\n  {\n    \"id\": 2,\n    \"name\": \"service2\"\n  }\n]\n"
+	if instances := get(t, handler, "/instances"); expectedInstances != instances {
+		t.Errorf("Wrong instances: %s", instances)
+	}
+
+	var timings []Timing
+	if decodeError := json.Unmarshal([]byte(get(t, handler, "/timings")), &timings); nil != decodeError {
+		t.Fatalf("Failed to decode timings: %s", decodeError)
+	}
+	if 2 != len(timings) || 1 != timings[0].Builds || "service2" != timings[1].Name {
+		t.Errorf("Wrong timings: %+v", timings)
+	}
+
+	var services []gioc.ServiceDescriptor
+	if decodeError := json.Unmarshal([]byte(get(t, handler, "/services")), &services); nil != decodeError {
+		t.Fatalf("Failed to decode services: %s", decodeError)
+	}
+	if 2 != len(services) || "@service1,#db.password" != strings.Join(services[1].Arguments, ",") {
+		t.Errorf("Wrong services: %+v", services)
+	}
+
+	var graph gioc.Graph
+	if decodeError := json.Unmarshal([]byte(get(t, handler, "/graph")), &graph); nil != decodeError {
+		t.Fatalf("Failed to decode graph: %s", decodeError)
+	}
+	if 2 != len(graph.Nodes) || 2 != len(graph.Edges) {
+		t.Errorf("Wrong graph: %+v", graph)
+	}
+
+	if dot := get(t, handler, "/graph.dot"); !strings.Contains(dot, "s2 -> s1") || strings.Contains(dot, "qwerty") {
+		t.Errorf("Wrong DOT graph: %s", dot)
+	}
+
+	if index := get(t, handler, "/"); !strings.Contains(index, "\"/services\"") {
+		t.Errorf("Wrong index: %s", index)
+	}
+}
@@ -0,0 +1,223 @@
+package gioc
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Definition is a mutable description of registered service, compiler passes (see ContainerBuilder.AddCompilerPass)
+// read and change definitions before Container is built. Changes of definition do not affect ContainerBuilder.
+type Definition struct {
+	Factory        *Factory
+	CachingEnabled bool
+	// Private service can be injected as dependency only (see Container.MarkPrivate)
+	Private bool
+	// Labels of service (see ContainerBuilder.Tag), like "event.subscriber"
+	Tags    []string
+	Aliases []string
+	// Types service is registered by (see Container.RegisterServiceFactoryByObject)
+	Types []reflect.Type
+	// Decorators in order they are applied (see Container.Decorate)
+	Decorators []*Factory
+
+	id int
+	// Factory of ready-made instance (see Container.RegisterInstance), nil for other services.
+	// Definition stays an instance while its Factory is not changed.
+	instanceFactory *Factory
+}
+
+// Returns human readable name: first alias in alphabetical order or type if service has no aliases
+func (d *Definition) Name() string {
+	aliases := append([]string(nil), d.Aliases...)
+	sort.Strings(aliases)
+
+	return entryName(aliases, d.Types)
+}
+
+func (d *Definition) HasTag(tag string) bool {
+	for _, definitionTag := range d.Tags {
+		if definitionTag == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Definitions are definitions of all services registered to ContainerBuilder, ordered by registration
+type Definitions struct {
+	definitions []*Definition
+}
+
+func (d *Definitions) All() []*Definition {
+	return append([]*Definition(nil), d.definitions...)
+}
+
+// Returns definition of service with alias, nil if there is no such service
+func (d *Definitions) Get(alias string) *Definition {
+	for _, definition := range d.definitions {
+		for _, definitionAlias := range definition.Aliases {
+			if definitionAlias == alias {
+				return definition
+			}
+		}
+	}
+
+	return nil
+}
+
+// Returns definition of service registered by type of serviceObj, nil if there is no such service
+func (d *Definitions) GetByObject(serviceObj interface{}) *Definition {
+	serviceType := reflect.TypeOf(serviceObj)
+	for _, definition := range d.definitions {
+		for _, definitionType := range definition.Types {
+			if definitionType == serviceType {
+				return definition
+			}
+		}
+	}
+
+	return nil
+}
+
+// Returns definitions of services with tag, ordered by registration
+func (d *Definitions) Tagged(tag string) []*Definition {
+	result := make([]*Definition, 0)
+	for _, definition := range d.definitions {
+		if definition.HasTag(tag) {
+			result = append(result, definition)
+		}
+	}
+
+	return result
+}
+
+// Adds definition of new service. Definition must have Factory and at least one alias or type.
+func (d *Definitions) Add(definition *Definition) {
+	d.definitions = append(d.definitions, definition)
+}
+
+func (d *Definitions) Remove(definition *Definition) {
+	for definitionNum, existing := range d.definitions {
+		if existing == definition {
+			d.definitions = append(d.definitions[:definitionNum], d.definitions[definitionNum+1:]...)
+
+			return
+		}
+	}
+}
+
+// Creates registry from definitions. Returns errors for definitions which can not be registered.
+func (d *Definitions) registry(servicesCounter int) (*registry, []error) {
+	result := newRegistry()
+	result.servicesCounter = servicesCounter
+	errorsList := make([]error, 0)
+	owners := make(map[interface{}]*Definition)
+
+	for _, definition := range d.definitions {
+		if definitionError := checkDefinition(definition); nil != definitionError {
+			errorsList = append(errorsList, definitionError)
+			continue
+		}
+
+		entry := &registryEntry{
+			factory:        definition.Factory,
+			cachingEnabled: definition.CachingEnabled,
+			id:             definition.id,
+			decorators:     append([]*Factory(nil), definition.Decorators...),
+			tags:           append([]string(nil), definition.Tags...),
+			private:        definition.Private,
+			isInstance:     nil != definition.instanceFactory && definition.instanceFactory == definition.Factory,
+		}
+		for _, alias := range definition.Aliases {
+			if owner, isOwned := owners[alias]; isOwned {
+				errorsList = append(
+					errorsList,
+					fmt.Errorf("alias '%s' is used by services '%s' and '%s'", alias, owner.Name(), definition.Name()),
+				)
+				continue
+			}
+			owners[alias] = definition
+			result.writeAlias(alias, entry)
+		}
+		for _, typeObj := range definition.Types {
+			if owner, isOwned := owners[typeObj]; isOwned {
+				errorsList = append(
+					errorsList,
+					fmt.Errorf("type %s is used by services '%s' and '%s'", typeObj.String(), owner.Name(), definition.Name()),
+				)
+				continue
+			}
+			owners[typeObj] = definition
+			result.writeType(typeObj, entry)
+		}
+	}
+
+	return result, errorsList
+}
+
+// Checks definition the same way registration methods of Container check their arguments
+func checkDefinition(definition *Definition) (definitionError error) {
+	if 0 == len(definition.Aliases) && 0 == len(definition.Types) {
+		return fmt.Errorf("service with id %d has no aliases and types", definition.id)
+	}
+	if nil == definition.Factory {
+		return fmt.Errorf("service '%s': factory not set", definition.Name())
+	}
+
+	defer func() {
+		if recovered := recover(); nil != recovered {
+			definitionError = fmt.Errorf("service '%s': %v", definition.Name(), recovered)
+		}
+	}()
+	checkFactoryMethod(definition.Factory.Create)
+	for _, decorator := range definition.Decorators {
+		checkDecoratorMethod(decorator.Create, reflect.TypeOf(definition.Factory.Create).Out(0))
+	}
+
+	return nil
+}
+
+// Returns definitions of registered services. Factories are copied, so compiler passes can change their arguments.
+func newDefinitions(r *registry) *Definitions {
+	aliases, types := r.keys()
+	result := &Definitions{definitions: make([]*Definition, 0)}
+
+	copyFactory := func(factory *Factory) *Factory {
+		return &Factory{Arguments: append([]string(nil), factory.Arguments...), Create: factory.Create}
+	}
+	for _, entry := range r.entries() {
+		definition := &Definition{
+			Factory:        copyFactory(entry.factory),
+			CachingEnabled: entry.cachingEnabled,
+			Private:        entry.private,
+			Tags:           append([]string(nil), entry.tags...),
+			Aliases:        append([]string(nil), aliases[entry]...),
+			Types:          append([]reflect.Type(nil), types[entry]...),
+			Decorators:     make([]*Factory, 0, len(entry.decorators)),
+			id:             entry.id,
+		}
+		sort.Slice(definition.Types, func(i, j int) bool {
+			return definition.Types[i].String() < definition.Types[j].String()
+		})
+		for _, decorator := range entry.decorators {
+			definition.Decorators = append(definition.Decorators, copyFactory(decorator))
+		}
+		if entry.isInstance {
+			definition.instanceFactory = definition.Factory
+		}
+		result.definitions = append(result.definitions, definition)
+	}
+
+	return result
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+// CompilerPass changes definitions of services before Container is built (see ContainerBuilder.AddCompilerPass).
+// Typical passes collect tagged services into arguments of other service, remove unused services or check naming
+// rules. Returned error fails the build.
+type CompilerPass func(definitions *Definitions) error
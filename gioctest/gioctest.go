@@ -0,0 +1,111 @@
+// Package gioctest contains helpers for tests of code wired with gioc.Container.
+package gioctest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bassbeaver/gioc"
+)
+
+// Override replaces factory of service registered with alias (string) or type (object) with fakeFactory for
+// the duration of test. Original factory is restored by t.Cleanup. Cached instances of the service and of all
+// services depending on it are dropped both on override and on restore (see gioc.Container.Replace).
+func Override(t testing.TB, c *gioc.Container, aliasOrType interface{}, fakeFactory interface{}) {
+	t.Helper()
+
+	var previous gioc.Factory
+	if replaceError := catchPanic(func() { previous = c.Replace(aliasOrType, fakeFactory) }); nil != replaceError {
+		t.Fatalf("gioctest: failed to override %v: %s", aliasOrType, replaceError)
+		return
+	}
+
+	t.Cleanup(func() {
+		c.Replace(aliasOrType, previous)
+	})
+}
+
+// RequireResolvable validates Container (see gioc.Container.Validate) and builds every service, all problems are
+// reported at once. Test fails immediately if any service can not be resolved.
+// Services with disabled caching and without aliases are validated only.
+func RequireResolvable(t testing.TB, c *gioc.Container) {
+	t.Helper()
+
+	failures := make([]string, 0)
+	for _, validationError := range c.Validate() {
+		failures = append(failures, validationError.Error())
+	}
+
+	// Building services of invalid Container would only repeat validation errors
+	if 0 == len(failures) {
+		if warmupError, isWarmupError := c.Warmup(context.Background(), 0).(*gioc.WarmupError); isWarmupError {
+			for _, serviceError := range warmupError.Errors {
+				failures = append(failures, serviceError.Error())
+			}
+		}
+
+		notCached := make([]gioc.ServiceDescriptor, 0)
+		aliases := make([]string, 0)
+		for _, descriptor := range c.Services() {
+			if !descriptor.CachingEnabled && len(descriptor.Aliases) > 0 {
+				notCached = append(notCached, descriptor)
+				aliases = append(aliases, descriptor.Aliases[0])
+			}
+		}
+		if len(notCached) > 0 {
+			probe, locator := injectedLocator(c, aliases)
+			defer probe.Close()
+			for _, descriptor := range notCached {
+				if resolveError := catchPanic(func() { locator.Get(descriptor.Aliases[0]) }); nil != resolveError {
+					failures = append(failures, fmt.Sprintf("service '%s': %s", descriptor.Name, resolveError))
+				}
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		t.Fatalf("gioctest: %d problems found:\n%s", len(failures), strings.Join(failures, "\n"))
+	}
+}
+
+// NewIsolated returns clone of Container (see gioc.Container.Clone) closed by t.Cleanup. Clone has same
+// definitions, but does not share instances of services with c and other clones, so parallel tests do not
+// affect each other.
+func NewIsolated(t testing.TB, c *gioc.Container) *gioc.Container {
+	t.Helper()
+
+	clone := c.Clone()
+	t.Cleanup(clone.Close)
+
+	return clone
+}
+
+// Returns Locator injected into factory of probe service, such Locator gives access to private services too.
+// Probe is registered in child Container, so c is not changed. Child Container must be closed after use.
+func injectedLocator(c *gioc.Container, aliases []string) (*gioc.Container, *gioc.Locator) {
+	probe := gioc.NewChildContainer(c)
+	probe.RegisterServiceFactoryByAlias(
+		"gioctest.probe",
+		gioc.Factory{
+			Create:    func(locator *gioc.Locator) *gioc.Locator { return locator },
+			Arguments: []string{"locator:" + strings.Join(aliases, ",")},
+		},
+		false,
+	)
+
+	return probe, probe.GetByAlias("gioctest.probe").(*gioc.Locator)
+}
+
+func catchPanic(function func()) (panicError error) {
+	defer func() {
+		if recovered := recover(); nil != recovered {
+			panicError = fmt.Errorf("%v", recovered)
+		}
+	}()
+
+	function()
+
+	return nil
+}
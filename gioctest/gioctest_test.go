@@ -0,0 +1,141 @@
+package gioctest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bassbeaver/gioc"
+)
+
+type testStorage interface {
+	Name() string
+}
+
+type testNamedStorage struct {
+	name string
+}
+
+func (s *testNamedStorage) Name() string {
+	return s.name
+}
+
+type testService struct {
+	Storage testStorage
+}
+
+// Records failures instead of failing test
+type recordingT struct {
+	testing.TB
+	failures []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Fatalf(format string, args ...interface{}) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func newTestContainer() *gioc.Container {
+	c := gioc.NewContainer()
+	c.RegisterServiceFactoryByAlias(
+		"storage",
+		func() testStorage { return &testNamedStorage{name: "real"} },
+		true,
+	).RegisterServiceFactoryByAlias(
+		"service",
+		gioc.Factory{
+			Create:    func(s testStorage) *testService { return &testService{Storage: s} },
+			Arguments: []string{"@storage"},
+		},
+		true,
+	)
+
+	return c
+}
+
+func TestOverride(t *testing.T) {
+	c := newTestContainer()
+	defer c.Close()
+	c.GetByAlias("service")
+
+	t.Run("override", func(t *testing.T) {
+		Override(t, c, "storage", func() testStorage { return &testNamedStorage{name: "fake"} })
+
+		if "fake" != c.GetByAlias("service").(*testService).Storage.Name() {
+			t.Errorf("Dependent service got real storage")
+		}
+	})
+
+	if "real" != c.GetByAlias("service").(*testService).Storage.Name() {
+		t.Errorf("Override was not restored")
+	}
+
+	recorder := &recordingT{TB: t}
+	Override(recorder, c, "missing", func() testStorage { return nil })
+	if 1 != len(recorder.failures) || !strings.Contains(recorder.failures[0], "missing") {
+		t.Errorf("Override of not registered service did not fail test: %v", recorder.failures)
+	}
+}
+
+func TestRequireResolvable(t *testing.T) {
+	c := newTestContainer()
+	defer c.Close()
+
+	RequireResolvable(t, c)
+
+	c.RegisterServiceFactoryByAlias(
+		"broken",
+		func() *testService { panic("no connection") },
+		false,
+	).RegisterServiceFactoryByAlias(
+		"misconfigured",
+		gioc.Factory{
+			Create:    func(s testStorage) *testService { return &testService{Storage: s} },
+			Arguments: []string{"@missing"},
+		},
+		true,
+	)
+
+	recorder := &recordingT{TB: t}
+	RequireResolvable(recorder, c)
+	if 1 != len(recorder.failures) || !strings.Contains(recorder.failures[0], "service with alias 'missing' not registered") {
+		t.Errorf("Validation problems were not reported: %v", recorder.failures)
+	}
+
+	// Private services are built too
+	c.Remove("misconfigured")
+	c.MarkPrivate("broken")
+	recorder = &recordingT{TB: t}
+	RequireResolvable(recorder, c)
+	if 1 != len(recorder.failures) || !strings.Contains(recorder.failures[0], "service 'broken'") ||
+		!strings.Contains(recorder.failures[0], "no connection") {
+		t.Errorf("Failed factory was not reported: %v", recorder.failures)
+	}
+}
+
+func TestNewIsolated(t *testing.T) {
+	c := newTestContainer()
+	defer c.Close()
+	shared := c.GetByAlias("service")
+
+	// Group finishes when all parallel tests finish, so c is closed after them
+	t.Run("group", func(t *testing.T) {
+		for testNum := 0; testNum < 3; testNum++ {
+			t.Run(fmt.Sprintf("parallel_%d", testNum), func(t *testing.T) {
+				t.Parallel()
+				isolated := NewIsolated(t, c)
+				Override(t, isolated, "storage", func() testStorage { return &testNamedStorage{name: t.Name()} })
+
+				service := isolated.GetByAlias("service").(*testService)
+				if service == shared || t.Name() != service.Storage.Name() {
+					t.Errorf("Isolated container shares services")
+				}
+			})
+		}
+	})
+
+	if "real" != c.GetByAlias("service").(*testService).Storage.Name() {
+		t.Errorf("Override of isolated container changed original container")
+	}
+}
@@ -0,0 +1,270 @@
+package gioc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GraphNode is a registered service. Service with multiple aliases is one node.
+type GraphNode struct {
+	ID int `json:"id"`
+	// Human readable name: first alias in alphabetical order or registration type if service has no aliases
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+	// Types service is registered by (see Container.RegisterServiceFactoryByObject)
+	Types          []string `json:"types"`
+	CachingEnabled bool     `json:"caching_enabled"`
+	// Signature of factory method, like "func(string, *pkg.Service1) *pkg.Service2"
+	Factory string `json:"factory"`
+	// Signatures of decorators in order they are applied (see Container.Decorate)
+	Decorators []string `json:"decorators,omitempty"`
+}
+
+// GraphEdge is a dependency created by factory argument
+type GraphEdge struct {
+	// ID of dependent node
+	From int `json:"from"`
+	// ID of node dependency is resolved to, 0 for parameters and not registered services
+	To        int            `json:"to"`
+	Argument  int            `json:"argument"`
+	Kind      DependencyKind `json:"kind"`
+	Alias     string         `json:"alias,omitempty"`
+	Type      string         `json:"type,omitempty"`
+	Parameter string         `json:"parameter,omitempty"`
+	Optional  bool           `json:"optional"`
+	// True for dependency got after service is built (see Locator)
+	Lazy bool `json:"lazy,omitempty"`
+	// Position of decorator (starting from 1) which argument creates dependency, 0 for service factory arguments
+	Decorator int `json:"decorator,omitempty"`
+	// True if dependency is service of parent Container (see NewChildContainer), To is 0 then
+	Parent bool `json:"parent,omitempty"`
+}
+
+// Graph is a snapshot of Container's services and dependencies between them.
+// Nodes are ordered by id, edges by dependent node id and argument position, so exports are stable.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// Returns sorted names of parameters used by services
+func (g *Graph) parameters() []string {
+	unique := make(map[string]bool)
+	result := make([]string, 0)
+	for _, edge := range g.Edges {
+		if ParameterDependency == edge.Kind && !unique[edge.Parameter] {
+			unique[edge.Parameter] = true
+			result = append(result, edge.Parameter)
+		}
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func (e *GraphEdge) target() string {
+	switch e.Kind {
+	case AliasDependency:
+		return "@" + e.Alias
+	case ParameterDependency:
+		return "#" + e.Parameter
+	}
+
+	return e.Type
+}
+
+// Label of node for dependency which is not a node of graph: service of parent Container or not registered service
+func (e *GraphEdge) outsideTarget() string {
+	if e.Parent {
+		return "parent " + e.target()
+	}
+
+	return e.target()
+}
+
+func (e *GraphEdge) label() string {
+	label := "arg " + strconv.Itoa(e.Argument)
+	if e.Decorator > 0 {
+		label = "decorator " + strconv.Itoa(e.Decorator) + " " + label
+	}
+	if e.Lazy {
+		label += " (lazy)"
+	} else if e.Optional {
+		label += " (optional)"
+	}
+
+	return label
+}
+
+// Writes graph in Graphviz DOT format
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("digraph gioc {\n")
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tnode [shape=box];\n")
+	for _, node := range g.Nodes {
+		label := node.Name + "\n" + node.Factory
+		for _, decorator := range node.Decorators {
+			label += "\ndecorated by " + decorator
+		}
+		style := ""
+		if !node.CachingEnabled {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&b, "\ts%d [label=%s%s];\n", node.ID, strconv.Quote(label), style)
+	}
+	for paramNum, parameter := range g.parameters() {
+		fmt.Fprintf(&b, "\tp%d [label=%s, shape=note];\n", paramNum, strconv.Quote("#"+parameter))
+	}
+	missingNum := 0
+	parameterIds := g.parameterIds("p")
+	for _, edge := range g.Edges {
+		var target string
+		switch {
+		case ParameterDependency == edge.Kind:
+			target = parameterIds[edge.Parameter]
+		case 0 == edge.To:
+			target = "m" + strconv.Itoa(missingNum)
+			missingNum++
+			color := "red"
+			if edge.Parent {
+				color = "gray"
+			}
+			fmt.Fprintf(&b, "\t%s [label=%s, color=%s];\n", target, strconv.Quote(edge.outsideTarget()), color)
+		default:
+			target = "s" + strconv.Itoa(edge.To)
+		}
+		style := ""
+		if edge.Optional {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&b, "\ts%d -> %s [label=%s%s];\n", edge.From, target, strconv.Quote(edge.label()), style)
+	}
+	b.WriteString("}\n")
+
+	_, writeError := io.WriteString(w, b.String())
+
+	return writeError
+}
+
+// Writes graph as Mermaid flowchart
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("flowchart LR\n")
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&b, "    s%d[\"%s\"]\n", node.ID, mermaidEscape(node.Name))
+	}
+	for paramNum, parameter := range g.parameters() {
+		fmt.Fprintf(&b, "    p%d{{\"%s\"}}\n", paramNum, mermaidEscape("#"+parameter))
+	}
+	missingNum := 0
+	parameterIds := g.parameterIds("p")
+	for _, edge := range g.Edges {
+		var target string
+		switch {
+		case ParameterDependency == edge.Kind:
+			target = parameterIds[edge.Parameter]
+		case 0 == edge.To:
+			target = "m" + strconv.Itoa(missingNum)
+			missingNum++
+			fmt.Fprintf(&b, "    %s[/\"%s\"/]\n", target, mermaidEscape(edge.outsideTarget()))
+		default:
+			target = "s" + strconv.Itoa(edge.To)
+		}
+		arrow := "-->"
+		if edge.Optional {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "    s%d %s|\"%s\"| %s\n", edge.From, arrow, mermaidEscape(edge.label()), target)
+	}
+
+	_, writeError := io.WriteString(w, b.String())
+
+	return writeError
+}
+
+// Writes graph as JSON document
+func (g *Graph) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(g)
+}
+
+func (g *Graph) parameterIds(idPrefix string) map[string]string {
+	result := make(map[string]string)
+	for paramNum, parameter := range g.parameters() {
+		result[parameter] = idPrefix + strconv.Itoa(paramNum)
+	}
+
+	return result
+}
+
+func mermaidEscape(text string) string {
+	return strings.NewReplacer("\"", "#quot;", "<", "#lt;", ">", "#gt;").Replace(text)
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+func buildGraph(c *Container) *Graph {
+	graph := &Graph{
+		Nodes: make([]GraphNode, 0),
+		Edges: make([]GraphEdge, 0),
+	}
+
+	aliases, types := c.registry.keys()
+	for _, entry := range c.registry.entries() {
+		node := GraphNode{
+			ID:             entry.id,
+			Aliases:        aliases[entry],
+			Types:          make([]string, 0, len(types[entry])),
+			CachingEnabled: entry.cachingEnabled,
+			Factory:        reflect.TypeOf(entry.factory.Create).String(),
+		}
+		for _, typeObj := range types[entry] {
+			node.Types = append(node.Types, typeObj.String())
+		}
+		sort.Strings(node.Types)
+		if nil == node.Aliases {
+			node.Aliases = make([]string, 0)
+		}
+		node.Name = entryName(node.Aliases, types[entry])
+		for _, decorator := range entry.decorators {
+			node.Decorators = append(node.Decorators, reflect.TypeOf(decorator.Create).String())
+		}
+		graph.Nodes = append(graph.Nodes, node)
+
+		for _, dependency := range c.entryDependencies(entry) {
+			edge := GraphEdge{
+				From:      entry.id,
+				Argument:  dependency.Argument,
+				Kind:      dependency.Kind,
+				Alias:     dependency.Alias,
+				Parameter: dependency.Parameter,
+				Optional:  dependency.Optional,
+				Lazy:      dependency.Lazy,
+				Decorator: dependency.Decorator,
+			}
+			if nil != dependency.Type {
+				edge.Type = dependency.Type.String()
+			}
+			if dependencyEntry, owner := c.lookupDependency(dependency); nil != dependencyEntry {
+				if owner == c {
+					edge.To = dependencyEntry.id
+				} else {
+					edge.Parent = true
+				}
+			}
+			graph.Edges = append(graph.Edges, edge)
+		}
+	}
+
+	return graph
+}
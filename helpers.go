@@ -1,8 +1,11 @@
 package gioc
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"reflect"
+	"runtime"
 	"strconv"
 )
 
@@ -38,16 +41,61 @@ func checkFactoryMethod(factoryMethod interface{}) {
 	}
 }
 
-func getArgumentValueFromString(kind reflect.Kind, stringValue string) interface{} {
-	errorProcessor := func(err error, kind reflect.Kind, stringValue string) {
-		if err != nil {
-			panic("Failed to convert '" + stringValue + "' to " + kind.String())
+// Service instance matches entry if it can be returned by entry factory
+func isInstanceOfEntry(serviceObj interface{}, entry *registryEntry) bool {
+	serviceType := reflect.TypeOf(serviceObj)
+
+	return nil != serviceType && serviceType.AssignableTo(reflect.TypeOf(entry.factory.Create).Out(0))
+}
+
+// Creates cached entry for ready-made service instance. Entry gets factory returning the instance, so it is handled
+// like any other entry (for example decorators are applied to the instance when cache is dropped).
+// Factory returns type of existing entry if instance replaces it, so signature of service does not change.
+func newInstanceEntry(serviceObj interface{}, existing *registryEntry, subject string) *registryEntry {
+	serviceType := reflect.TypeOf(serviceObj)
+	if nil == serviceType || reflect.Ptr != serviceType.Kind() {
+		panic("Instance must be pointer")
+	}
+	if nil != existing {
+		if !isInstanceOfEntry(serviceObj, existing) {
+			panic("Instance is not same type as " + subject)
 		}
+		serviceType = reflect.TypeOf(existing.factory.Create).Out(0)
+	}
+
+	serviceValue := reflect.ValueOf(serviceObj)
+	factoryMethod := reflect.MakeFunc(
+		reflect.FuncOf([]reflect.Type{}, []reflect.Type{serviceType}, false),
+		func([]reflect.Value) []reflect.Value {
+			return []reflect.Value{serviceValue}
+		},
+	)
+
+	return &registryEntry{
+		factory:        &Factory{Create: factoryMethod.Interface()},
+		cachingEnabled: true,
+		cachedService:  serviceObj,
+		isInstance:     true,
 	}
+}
+
+// Decorator method gets decorated service as first argument and returns service of same type
+func checkDecoratorMethod(decoratorMethod interface{}, serviceType reflect.Type) {
+	checkFactoryMethod(decoratorMethod)
+
+	decoratorMethodType := reflect.TypeOf(decoratorMethod)
+	if decoratorMethodType.NumIn() < 1 || !serviceType.AssignableTo(decoratorMethodType.In(0)) {
+		panic("Decorator must accept decorated service of type " + serviceType.String() + " as first argument")
+	}
+	if !decoratorMethodType.Out(0).AssignableTo(serviceType) {
+		panic("Decorator must return service of type " + serviceType.String())
+	}
+}
 
+func getArgumentValueFromString(kind reflect.Kind, stringValue string) (interface{}, error) {
 	switch kind {
 	case reflect.String:
-		return stringValue
+		return stringValue, nil
 	case reflect.Int:
 		fallthrough
 	case reflect.Int8:
@@ -58,18 +106,20 @@ func getArgumentValueFromString(kind reflect.Kind, stringValue string) interface
 		fallthrough
 	case reflect.Int64:
 		intVal, conversionError := strconv.ParseInt(stringValue, 0, 64)
-		errorProcessor(conversionError, kind, stringValue)
+		if nil != conversionError {
+			return nil, conversionError
+		}
 		switch kind {
 		case reflect.Int:
-			return int(intVal)
+			return int(intVal), nil
 		case reflect.Int8:
-			return int8(intVal)
+			return int8(intVal), nil
 		case reflect.Int16:
-			return int16(intVal)
+			return int16(intVal), nil
 		case reflect.Int32:
-			return int32(intVal)
+			return int32(intVal), nil
 		}
-		return intVal
+		return intVal, nil
 	case reflect.Uint:
 		fallthrough
 	case reflect.Uint8:
@@ -80,34 +130,61 @@ func getArgumentValueFromString(kind reflect.Kind, stringValue string) interface
 		fallthrough
 	case reflect.Uint64:
 		intVal, conversionError := strconv.ParseUint(stringValue, 0, 64)
-		errorProcessor(conversionError, kind, stringValue)
+		if nil != conversionError {
+			return nil, conversionError
+		}
 		switch kind {
 		case reflect.Uint:
-			return uint(intVal)
+			return uint(intVal), nil
 		case reflect.Uint8:
-			return uint8(intVal)
+			return uint8(intVal), nil
 		case reflect.Uint16:
-			return uint16(intVal)
+			return uint16(intVal), nil
 		case reflect.Uint32:
-			return uint32(intVal)
+			return uint32(intVal), nil
 		}
-		return intVal
+		return intVal, nil
 	case reflect.Float32:
 		fallthrough
 	case reflect.Float64:
 		floatVal, conversionError := strconv.ParseFloat(stringValue, 64)
-		errorProcessor(conversionError, kind, stringValue)
+		if nil != conversionError {
+			return nil, conversionError
+		}
 		switch kind {
 		case reflect.Float32:
-			return float32(floatVal)
+			return float32(floatVal), nil
 		}
-		return floatVal
+		return floatVal, nil
 	case reflect.Bool:
-		boolVal, conversionError := strconv.ParseBool(stringValue)
-		errorProcessor(conversionError, kind, stringValue)
-		return boolVal
-	default:
-		errorProcessor(errors.New("no conversion logic found"), kind, stringValue)
+		return strconv.ParseBool(stringValue)
+	}
+
+	return nil, errors.New("no conversion logic found for kind " + kind.String())
+}
+
+// Returns id of current goroutine, parsed from first line of its stack trace ("goroutine 18 [running]:").
+// Go does not provide goroutine-local storage, this id is used to track which service current goroutine builds.
+func currentGoroutineId() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if spacePos := bytes.IndexByte(buf, ' '); spacePos >= 0 {
+		buf = buf[:spacePos]
 	}
-	return nil
+
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+
+	return id
+}
+
+// Calls factory method, panic of factory method is returned as error
+func callFactory(factoryMethodValue reflect.Value, arguments []reflect.Value) (service interface{}, callError error) {
+	defer func() {
+		if recovered := recover(); nil != recovered {
+			service, callError = nil, fmt.Errorf("%v", recovered)
+		}
+	}()
+
+	return factoryMethodValue.Call(arguments)[0].Interface(), nil
 }
@@ -0,0 +1,101 @@
+package gioc
+
+import (
+	"reflect"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// ServiceDescriptor describes registered service. Service with multiple aliases has one descriptor.
+type ServiceDescriptor struct {
+	ID int `json:"id"`
+	// Human readable name, same as GraphNode.Name
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+	// Types service is registered by (see Container.RegisterServiceFactoryByObject)
+	Types []string `json:"types"`
+	// Type of value returned by factory method
+	ServiceType string `json:"service_type"`
+	// Full name of factory method, like "main.NewService" or "main.main.func1" for anonymous functions
+	FactoryName string `json:"factory_name"`
+	// Source file and line factory method is defined at, empty if unknown
+	FactoryFile string   `json:"factory_file"`
+	FactoryLine int      `json:"factory_line"`
+	Arguments   []string `json:"arguments"`
+	// Full names of decorators in order they are applied (see Container.Decorate)
+	Decorators []string `json:"decorators,omitempty"`
+	// Tags in order they were added (see ContainerBuilder.Tag)
+	Tags           []string `json:"tags,omitempty"`
+	CachingEnabled bool     `json:"caching_enabled"`
+	// True if service can be injected as dependency only (see Container.MarkPrivate)
+	Private bool `json:"private"`
+	// True if cached service instance exists
+	Instantiated bool `json:"instantiated"`
+	// True if service is ready-made instance (see Container.RegisterInstance), factory fields are empty then
+	Instance bool `json:"instance"`
+	// How many times factory was run
+	Builds int `json:"builds"`
+	// Duration of last factory run, including resolution of factory arguments
+	BuildDuration time.Duration `json:"build_duration_ns"`
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+func describeEntry(entry *registryEntry, aliases []string, types []reflect.Type) ServiceDescriptor {
+	descriptor := ServiceDescriptor{
+		ID:             entry.id,
+		Name:           entryName(aliases, types),
+		Aliases:        make([]string, 0, len(aliases)),
+		Types:          make([]string, 0, len(types)),
+		Arguments:      make([]string, 0, len(entry.factory.Arguments)),
+		CachingEnabled: entry.cachingEnabled,
+		Private:        entry.private,
+		Instance:       entry.isInstance,
+	}
+	cachedService, _ := entry.cached()
+	descriptor.Instantiated = nil != cachedService
+	descriptor.Builds, descriptor.BuildDuration = entry.buildStats()
+	descriptor.Aliases = append(descriptor.Aliases, aliases...)
+	for _, typeObj := range types {
+		descriptor.Types = append(descriptor.Types, typeObj.String())
+	}
+	sort.Strings(descriptor.Types)
+	descriptor.Arguments = append(descriptor.Arguments, entry.factory.Arguments...)
+	descriptor.Tags = append(descriptor.Tags, entry.tags...)
+	for _, decorator := range entry.decorators {
+		descriptor.Decorators = append(descriptor.Decorators, functionName(decorator.Create))
+	}
+
+	factoryMethodValue := reflect.ValueOf(entry.factory.Create)
+	if factoryMethodValue.Type().NumOut() > 0 {
+		descriptor.ServiceType = factoryMethodValue.Type().Out(0).String()
+	}
+	if entry.isInstance {
+		return descriptor
+	}
+	if factoryFunc := runtime.FuncForPC(factoryMethodValue.Pointer()); nil != factoryFunc {
+		descriptor.FactoryName = factoryFunc.Name()
+		descriptor.FactoryFile, descriptor.FactoryLine = factoryFunc.FileLine(factoryFunc.Entry())
+	}
+
+	return descriptor
+}
+
+func describeContainer(c *Container) []ServiceDescriptor {
+	aliases, types := c.registry.keys()
+	result := make([]ServiceDescriptor, 0)
+	for _, entry := range c.registry.entries() {
+		result = append(result, describeEntry(entry, aliases[entry], types[entry]))
+	}
+
+	return result
+}
+
+func functionName(function interface{}) string {
+	if functionObj := runtime.FuncForPC(reflect.ValueOf(function).Pointer()); nil != functionObj {
+		return functionObj.Name()
+	}
+
+	return ""
+}
@@ -0,0 +1,119 @@
+package gioc
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Locator gives access to declared subset of Container's services. Services are got lazily, on Get call.
+// Factory receives Locator with argument definition "locator:alias1,alias2" (argument type must be *gioc.Locator),
+// such Locator gives access to private services too (see Container.MarkPrivate). Locator created by
+// Container.Locator gives access to public services only.
+type Locator struct {
+	container *Container
+	aliases   map[string]bool
+	// Injected Locator is a dependency of service, so it can access private services
+	allowPrivate bool
+}
+
+// Returns true if alias is declared for Locator and service with alias is registered and accessible by Locator
+func (l *Locator) Has(alias string) bool {
+	if !l.aliases[alias] {
+		return false
+	}
+	entry, _ := l.container.lookupAlias(alias)
+
+	return nil != entry && (l.allowPrivate || !entry.private)
+}
+
+// Returns service with alias, panics if alias is not declared for Locator, service is private and Locator was not
+// injected into factory or service can not be instantiated
+func (l *Locator) Get(alias string) interface{} {
+	if !l.aliases[alias] {
+		panic(fmt.Sprintf("Failed to get service '%s'. Service is not declared for locator", alias))
+	}
+	if !l.allowPrivate {
+		return l.container.GetByAlias(alias)
+	}
+
+	return l.container.getByAlias(alias)
+}
+
+// Returns declared aliases in alphabetical order
+func (l *Locator) Aliases() []string {
+	result := make([]string, 0, len(l.aliases))
+	for alias := range l.aliases {
+		result = append(result, alias)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Resolves "locator:alias1,alias2" definitions. Declared services are lazy and optional dependencies: they are shown
+// by graph and checked by Container.Validate, but do not create cycles, service getting itself from Locator inside
+// factory is detected on resolution only.
+type locatorArgumentResolver struct{}
+
+func (r locatorArgumentResolver) Resolve(c *Container, argument string, argumentType reflect.Type) (interface{}, error) {
+	if typeError := checkLocatorArgumentType(argumentType); nil != typeError {
+		return nil, typeError
+	}
+
+	return newLocator(c, locatorAliases(argument), true), nil
+}
+
+func (r locatorArgumentResolver) Dependencies(c *Container, argument string) []Dependency {
+	aliases := locatorAliases(argument)
+	dependencies := make([]Dependency, 0, len(aliases))
+	for _, alias := range aliases {
+		dependencies = append(dependencies, Dependency{Kind: AliasDependency, Alias: alias, Optional: true, Lazy: true})
+	}
+
+	return dependencies
+}
+
+// "locator:" prefix is reserved, so literal starting with it passed to argument of other type is reported instead of
+// failing factory call
+func checkLocatorArgumentType(argumentType reflect.Type) error {
+	if locatorType := reflect.TypeOf((*Locator)(nil)); !locatorType.AssignableTo(argumentType) {
+		return fmt.Errorf(
+			"locator can not be passed as %s, escape literal starting with \"%s\" with \"%s\"",
+			argumentType.String(),
+			argumentPrefixLocator,
+			argumentEscape,
+		)
+	}
+
+	return nil
+}
+
+func locatorAliases(argument string) []string {
+	aliases := make([]string, 0)
+	for _, alias := range strings.Split(argument, ",") {
+		if alias = strings.TrimSpace(alias); "" != alias {
+			aliases = append(aliases, alias)
+		}
+	}
+
+	return aliases
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+func newLocator(c *Container, aliases []string, allowPrivate bool) *Locator {
+	l := &Locator{
+		container:    c,
+		aliases:      make(map[string]bool, len(aliases)),
+		allowPrivate: allowPrivate,
+	}
+	for _, alias := range aliases {
+		l.aliases[alias] = true
+	}
+
+	return l
+}
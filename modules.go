@@ -0,0 +1,284 @@
+package gioc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Prefix of absolute alias passed to builder inside Module.Register
+const absoluteAliasPrefix = "/"
+
+// Module is a reusable block of registrations (for example logging, metrics or database wiring) shipped as library.
+// Module registers its services with builder passed to Register. Aliases passed to builder methods are relative to
+// module namespace: module "metrics" registering "registry" creates service "metrics.registry", registering
+// "http.handler" creates "metrics.http.handler". Aliases starting with "/" are absolute, so module can decorate, tag
+// or alias services of application and other modules ("/db.conn").
+// Factory arguments are not changed, so services are referenced by full aliases ("@metrics.registry"). Argument
+// referring to not registered service by alias relative to module ("@registry") is reported as error.
+type Module interface {
+	// Name of module, it is namespace of module aliases and is used in dependencies of other modules
+	Name() string
+	Register(b *ContainerBuilder) error
+}
+
+// ModuleWithParameters can be implemented by Module to provide default values of parameters.
+// Defaults are set before Register, parameters which are already set are not overwritten.
+type ModuleWithParameters interface {
+	DefaultParameters() map[string]string
+}
+
+// ModuleWithDependencies can be implemented by Module which uses services of other modules. Modules are installed
+// after modules they depend on. Dependencies must be installed before or with dependent module.
+type ModuleWithDependencies interface {
+	// Names of modules this module depends on
+	DependsOn() []string
+}
+
+// InstallError is returned by Container.Install, it contains errors of all modules which failed to install
+type InstallError struct {
+	Errors []error
+}
+
+func (e *InstallError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, installError := range e.Errors {
+		messages = append(messages, installError.Error())
+	}
+
+	return fmt.Sprintf("Modules installation failed with %d errors:\n", len(e.Errors)) + strings.Join(messages, "\n")
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Installs modules in order of their dependencies: every module is installed after modules it depends on, independent
+// modules keep order they were passed in. Module which is already installed is skipped, so several modules can list
+// common dependency. Module is marked installed only if it registered without errors. Errors are returned by Build().
+func (b *ContainerBuilder) Install(modules ...Module) *ContainerBuilder {
+	ordered, orderErrors := orderModules(modules, b.container.isModuleInstalled)
+	b.errors = append(b.errors, orderErrors...)
+
+	for _, module := range ordered {
+		if withParameters, hasParameters := module.(ModuleWithParameters); hasParameters {
+			defaults := withParameters.DefaultParameters()
+			b.apply(func(c *Container) {
+				for name, value := range defaults {
+					if !c.parameters.IsSet(name) {
+						c.parameters.set(name, value)
+					}
+				}
+			})
+		}
+
+		moduleBuilder := &ContainerBuilder{
+			container:      b.container,
+			errors:         make([]error, 0),
+			compilerPasses: make([]CompilerPass, 0),
+			namespace:      module.Name() + ".",
+			staging:        b.staging,
+			staged:         make([]func(c *Container), 0),
+		}
+		registerError := moduleBuilder.register(module)
+		if nil != registerError {
+			b.errors = append(b.errors, fmt.Errorf("module '%s' failed: %w", module.Name(), registerError))
+		} else {
+			moduleBuilder.errors = append(moduleBuilder.errors, moduleBuilder.checkModuleReferences()...)
+		}
+		b.errors = append(b.errors, moduleBuilder.errors...)
+		b.compilerPasses = append(b.compilerPasses, moduleBuilder.compilerPasses...)
+		b.staged = append(b.staged, moduleBuilder.staged...)
+
+		if nil == registerError && 0 == len(moduleBuilder.errors) {
+			name := module.Name()
+			b.apply(func(c *Container) { c.addInstalledModule(name) })
+		}
+	}
+
+	return b
+}
+
+// Registration methods panic on wrong registrations (for example on factory which is not a function), module panic
+// is returned as error, so one broken module does not hide problems of others
+func (b *ContainerBuilder) register(module Module) (registerError error) {
+	defer func() {
+		if recovered := recover(); nil != recovered {
+			registerError = fmt.Errorf("%v", recovered)
+		}
+	}()
+
+	return module.Register(b)
+}
+
+func (b *ContainerBuilder) addModuleFactory(factory interface{}) {
+	if "" != b.namespace {
+		b.moduleFactories = append(b.moduleFactories, factory)
+	}
+}
+
+// Factory arguments are not namespaced, so "@registry" of module "metrics" refers to application service
+// "registry". Reference to not registered service which is registered with namespace of module is a mistake.
+func (b *ContainerBuilder) checkModuleReferences() []error {
+	referenceErrors := make([]error, 0)
+	for _, factory := range b.moduleFactories {
+		factoryObj, isFactory := factory.(Factory)
+		if !isFactory {
+			continue
+		}
+		for _, definition := range factoryObj.Arguments {
+			argumentDefinition := parseArgumentDefinition(b.container.resolvers, definition)
+			if argumentDefinition.isLiteral() {
+				continue
+			}
+			for _, dependency := range argumentDefinition.resolver.Dependencies(b.container, argumentDefinition.name) {
+				if AliasDependency != dependency.Kind {
+					continue
+				}
+				if entry, _ := b.container.lookupAlias(dependency.Alias); nil != entry {
+					continue
+				}
+				if entry, _ := b.container.lookupAlias(b.namespace + dependency.Alias); nil != entry {
+					referenceErrors = append(
+						referenceErrors,
+						fmt.Errorf(
+							"argument '%s' refers to not registered service '%s', module service is referenced by full alias '%s'",
+							definition,
+							dependency.Alias,
+							b.namespace+dependency.Alias,
+						),
+					)
+				}
+			}
+		}
+	}
+
+	return referenceErrors
+}
+
+// Installs modules to Container (see ContainerBuilder.Install). Modules are installed to copy of Container first,
+// Container is changed only if all modules are installed without errors. Returns *InstallError with all found
+// problems.
+func (c *Container) Install(modules ...Module) error {
+	staging := c.Clone()
+	defer staging.Close()
+	// Registrations to sealed Container fail on staging too
+	staging.sealed = c.sealed
+
+	b := &ContainerBuilder{
+		container:      staging,
+		errors:         make([]error, 0),
+		compilerPasses: make([]CompilerPass, 0),
+		staging:        true,
+		staged:         make([]func(c *Container), 0),
+	}
+	b.Install(modules...)
+	if len(b.compilerPasses) > 0 {
+		b.errors = append(b.errors, errors.New("compiler passes can be added by modules installed to ContainerBuilder only"))
+	}
+	if len(b.errors) > 0 {
+		return &InstallError{Errors: b.errors}
+	}
+
+	for _, registration := range b.staged {
+		registration(c)
+	}
+
+	return nil
+}
+
+func (c *Container) isModuleInstalled(name string) bool {
+	c.modulesMutex.Lock()
+	defer c.modulesMutex.Unlock()
+
+	return c.modules[name]
+}
+
+func (c *Container) addInstalledModule(name string) {
+	c.modulesMutex.Lock()
+	defer c.modulesMutex.Unlock()
+
+	c.modules[name] = true
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Orders modules by dependencies with depth-first search. Modules with missing dependencies or dependency cycles
+// are not returned.
+func orderModules(modules []Module, isInstalled func(name string) bool) ([]Module, []error) {
+	result := make([]Module, 0, len(modules))
+	errorsList := make([]error, 0)
+
+	byName := make(map[string]Module, len(modules))
+	for _, module := range modules {
+		if "" == module.Name() {
+			errorsList = append(errorsList, fmt.Errorf("module %T has empty name", module))
+			continue
+		}
+		if _, isDuplicate := byName[module.Name()]; !isDuplicate {
+			byName[module.Name()] = module
+		}
+	}
+
+	// Module is visited while its dependencies are ordered, done when it is ordered or failed
+	visiting := make(map[string]bool)
+	done := make(map[string]bool)
+	failed := make(map[string]bool)
+	path := make([]string, 0)
+	var visit func(module Module) bool
+	visit = func(module Module) bool {
+		name := module.Name()
+		if done[name] {
+			return !failed[name]
+		}
+		if visiting[name] {
+			cycle := Cycle{}
+			for pathNum := len(path) - 1; pathNum >= 0; pathNum-- {
+				if path[pathNum] == name {
+					cycle = append(cycle, path[pathNum:]...)
+					break
+				}
+			}
+			errorsList = append(errorsList, fmt.Errorf("modules dependency cycle: %s", append(cycle, name).String()))
+
+			return false
+		}
+
+		visiting[name] = true
+		path = append(path, name)
+		isOrdered := true
+		if withDependencies, hasDependencies := module.(ModuleWithDependencies); hasDependencies {
+			for _, dependency := range withDependencies.DependsOn() {
+				if isInstalled(dependency) {
+					continue
+				}
+				dependencyModule, isPassed := byName[dependency]
+				if !isPassed {
+					errorsList = append(
+						errorsList,
+						fmt.Errorf("module '%s' depends on module '%s' which is not installed", name, dependency),
+					)
+					isOrdered = false
+					continue
+				}
+				isOrdered = visit(dependencyModule) && isOrdered
+			}
+		}
+		path = path[:len(path)-1]
+		visiting[name] = false
+
+		done[name] = true
+		failed[name] = !isOrdered
+		if isOrdered {
+			result = append(result, module)
+		}
+
+		return isOrdered
+	}
+
+	for _, module := range modules {
+		if "" != module.Name() && !isInstalled(module.Name()) {
+			visit(byName[module.Name()])
+		}
+	}
+
+	return result, errorsList
+}
@@ -0,0 +1,90 @@
+package gioc
+
+import (
+	"time"
+)
+
+// ServiceEvent is passed to Observer callbacks related to one service
+type ServiceEvent struct {
+	ServiceID int
+	// Human readable name of service, same as GraphNode.Name
+	Service string
+	// Only for ResolveEnd and FactoryEnd. Resolve duration includes waiting for service built by other goroutine
+	// and resolution of factory arguments, factory duration is duration of factory method call only.
+	Duration time.Duration
+	// Only for ResolveEnd and FactoryEnd
+	Error error
+}
+
+// ParameterEvent is passed to Observer.ParameterLookup
+type ParameterEvent struct {
+	Parameter string
+	Found     bool
+	Secret    bool
+}
+
+// Observer receives events of services resolution. Callbacks are called synchronously from goroutines resolving
+// services, so they should be fast and safe for concurrent use. Embed NopObserver to implement only needed callbacks.
+type Observer interface {
+	// Service is requested and it is not cached
+	ResolveStart(event ServiceEvent)
+	ResolveEnd(event ServiceEvent)
+	// Service is requested and cached instance is returned
+	CacheHit(event ServiceEvent)
+	// Factory method of service is about to be called, all factory arguments are resolved
+	FactoryStart(event ServiceEvent)
+	FactoryEnd(event ServiceEvent)
+	// Parameter is requested by #parameter argument definition
+	ParameterLookup(event ParameterEvent)
+}
+
+// NopObserver implements Observer with empty callbacks
+type NopObserver struct{}
+
+func (NopObserver) ResolveStart(event ServiceEvent)      {}
+func (NopObserver) ResolveEnd(event ServiceEvent)        {}
+func (NopObserver) CacheHit(event ServiceEvent)          {}
+func (NopObserver) FactoryStart(event ServiceEvent)      {}
+func (NopObserver) FactoryEnd(event ServiceEvent)        {}
+func (NopObserver) ParameterLookup(event ParameterEvent) {}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+// observersList passes events to every observer of the list
+type observersList []Observer
+
+func (l observersList) ResolveStart(event ServiceEvent) {
+	for _, observer := range l {
+		observer.ResolveStart(event)
+	}
+}
+
+func (l observersList) ResolveEnd(event ServiceEvent) {
+	for _, observer := range l {
+		observer.ResolveEnd(event)
+	}
+}
+
+func (l observersList) CacheHit(event ServiceEvent) {
+	for _, observer := range l {
+		observer.CacheHit(event)
+	}
+}
+
+func (l observersList) FactoryStart(event ServiceEvent) {
+	for _, observer := range l {
+		observer.FactoryStart(event)
+	}
+}
+
+func (l observersList) FactoryEnd(event ServiceEvent) {
+	for _, observer := range l {
+		observer.FactoryEnd(event)
+	}
+}
+
+func (l observersList) ParameterLookup(event ParameterEvent) {
+	for _, observer := range l {
+		observer.ParameterLookup(event)
+	}
+}
@@ -0,0 +1,3 @@
+// Package observers contains gioc.Observer implementations exporting services resolution events
+// to log/slog logger (Go 1.21+) and to expvar counters.
+package observers
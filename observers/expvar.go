@@ -0,0 +1,64 @@
+package observers
+
+import (
+	"expvar"
+
+	"github.com/bassbeaver/gioc"
+)
+
+// Keys of counters maintained by ExpvarObserver
+const (
+	CounterResolves          = "resolves"
+	CounterResolveErrors     = "resolve_errors"
+	CounterResolveDurationNs = "resolve_duration_ns"
+	CounterCacheHits         = "cache_hits"
+	CounterFactoryCalls      = "factory_calls"
+	CounterFactoryErrors     = "factory_errors"
+	CounterFactoryDurationNs = "factory_duration_ns"
+	CounterParameterLookups  = "parameter_lookups"
+	CounterParameterMisses   = "parameter_misses"
+)
+
+// ExpvarObserver counts resolution events in expvar.Map. Comparing resolve and factory durations shows how much
+// time is spent waiting for dependencies and for services built by other goroutines.
+type ExpvarObserver struct {
+	counters *expvar.Map
+}
+
+func (o *ExpvarObserver) ResolveStart(event gioc.ServiceEvent) {}
+
+func (o *ExpvarObserver) ResolveEnd(event gioc.ServiceEvent) {
+	o.counters.Add(CounterResolves, 1)
+	o.counters.Add(CounterResolveDurationNs, event.Duration.Nanoseconds())
+	if nil != event.Error {
+		o.counters.Add(CounterResolveErrors, 1)
+	}
+}
+
+func (o *ExpvarObserver) CacheHit(event gioc.ServiceEvent) {
+	o.counters.Add(CounterCacheHits, 1)
+}
+
+func (o *ExpvarObserver) FactoryStart(event gioc.ServiceEvent) {}
+
+func (o *ExpvarObserver) FactoryEnd(event gioc.ServiceEvent) {
+	o.counters.Add(CounterFactoryCalls, 1)
+	o.counters.Add(CounterFactoryDurationNs, event.Duration.Nanoseconds())
+	if nil != event.Error {
+		o.counters.Add(CounterFactoryErrors, 1)
+	}
+}
+
+func (o *ExpvarObserver) ParameterLookup(event gioc.ParameterEvent) {
+	o.counters.Add(CounterParameterLookups, 1)
+	if !event.Found {
+		o.counters.Add(CounterParameterMisses, 1)
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Counters are added to given map, use expvar.NewMap("gioc") to publish them
+func NewExpvarObserver(counters *expvar.Map) *ExpvarObserver {
+	return &ExpvarObserver{counters: counters}
+}
@@ -0,0 +1,50 @@
+package observers
+
+import (
+	"expvar"
+	"testing"
+
+	"github.com/bassbeaver/gioc"
+)
+
+type testService struct{}
+
+func newTestContainer() *gioc.Container {
+	c := gioc.NewContainer()
+	c.RegisterServiceFactoryByAlias(
+		"service",
+		gioc.Factory{
+			Create:    func(name string) *testService { return &testService{} },
+			Arguments: []string{"#name|default"},
+		},
+		true,
+	)
+
+	return c
+}
+
+func TestExpvarObserver(t *testing.T) {
+	counters := new(expvar.Map).Init()
+	c := newTestContainer()
+	defer c.Close()
+	c.AddObserver(NewExpvarObserver(counters))
+
+	c.GetByAlias("service")
+	c.GetByAlias("service")
+
+	expectedCounters := map[string]string{
+		CounterResolves:         "1",
+		CounterCacheHits:        "1",
+		CounterFactoryCalls:     "1",
+		CounterParameterLookups: "1",
+		CounterParameterMisses:  "1",
+	}
+	for key, expectedValue := range expectedCounters {
+		if value := counters.Get(key); nil == value || expectedValue != value.String() {
+			t.Errorf("Wrong value of counter %s: %v", key, value)
+		}
+	}
+	if nil != counters.Get(CounterFactoryErrors) {
+		t.Errorf("Factory errors counted for successful factory")
+	}
+}
@@ -0,0 +1,69 @@
+//go:build go1.21
+// +build go1.21
+
+package observers
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/bassbeaver/gioc"
+)
+
+// SlogObserver writes resolution events to slog.Logger. Successful events are logged with Debug level,
+// failed resolutions and factory calls - with Error level. Parameter values are never logged.
+type SlogObserver struct {
+	logger *slog.Logger
+}
+
+func (o *SlogObserver) ResolveStart(event gioc.ServiceEvent) {
+	o.logger.LogAttrs(context.Background(), slog.LevelDebug, "gioc: resolve start", serviceAttrs(event)...)
+}
+
+func (o *SlogObserver) ResolveEnd(event gioc.ServiceEvent) {
+	o.logService("gioc: resolve end", event)
+}
+
+func (o *SlogObserver) CacheHit(event gioc.ServiceEvent) {
+	o.logger.LogAttrs(context.Background(), slog.LevelDebug, "gioc: cache hit", serviceAttrs(event)...)
+}
+
+func (o *SlogObserver) FactoryStart(event gioc.ServiceEvent) {
+	o.logger.LogAttrs(context.Background(), slog.LevelDebug, "gioc: factory start", serviceAttrs(event)...)
+}
+
+func (o *SlogObserver) FactoryEnd(event gioc.ServiceEvent) {
+	o.logService("gioc: factory end", event)
+}
+
+func (o *SlogObserver) ParameterLookup(event gioc.ParameterEvent) {
+	o.logger.LogAttrs(
+		context.Background(),
+		slog.LevelDebug,
+		"gioc: parameter lookup",
+		slog.String("parameter", event.Parameter),
+		slog.Bool("found", event.Found),
+		slog.Bool("secret", event.Secret),
+	)
+}
+
+func (o *SlogObserver) logService(message string, event gioc.ServiceEvent) {
+	attrs := append(serviceAttrs(event), slog.Duration("duration", event.Duration))
+	level := slog.LevelDebug
+	if nil != event.Error {
+		level = slog.LevelError
+		attrs = append(attrs, slog.String("error", event.Error.Error()))
+	}
+
+	o.logger.LogAttrs(context.Background(), level, message, attrs...)
+}
+
+func serviceAttrs(event gioc.ServiceEvent) []slog.Attr {
+	return []slog.Attr{slog.Int("service_id", event.ServiceID), slog.String("service", event.Service)}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+func NewSlogObserver(logger *slog.Logger) *SlogObserver {
+	return &SlogObserver{logger: logger}
+}
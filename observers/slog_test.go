@@ -0,0 +1,50 @@
+//go:build go1.21
+// +build go1.21
+
+package observers
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogObserver(t *testing.T) {
+	var output strings.Builder
+	logger := slog.New(
+		slog.NewTextHandler(
+			&output,
+			&slog.HandlerOptions{
+				Level: slog.LevelDebug,
+				ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+					if slog.TimeKey == attr.Key || "duration" == attr.Key {
+						return slog.Attr{}
+					}
+					return attr
+				},
+			},
+		),
+	)
+	c := newTestContainer()
+	defer c.Close()
+	c.AddObserver(NewSlogObserver(logger))
+
+	c.GetByAlias("service")
+	c.GetByAlias("service")
+
+	expectedOutput := strings.Join(
+		[]string{
+			`level=DEBUG msg="gioc: resolve start" service_id=1 service=service`,
+			`level=DEBUG msg="gioc: parameter lookup" parameter=name found=false secret=false`,
+			`level=DEBUG msg="gioc: factory start" service_id=1 service=service`,
+			`level=DEBUG msg="gioc: factory end" service_id=1 service=service`,
+			`level=DEBUG msg="gioc: resolve end" service_id=1 service=service`,
+			`level=DEBUG msg="gioc: cache hit" service_id=1 service=service`,
+			``,
+		},
+		"\n",
+	)
+	if expectedOutput != output.String() {
+		t.Errorf("Wrong log:\n%s", output.String())
+	}
+}
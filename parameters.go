@@ -1,24 +1,89 @@
 package gioc
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
 	"sync"
 )
 
+// Value shown instead of secret parameters in error messages, dumps and exports
+const RedactedValue = "******"
+
+// Reference to other parameter in parameter value, see ContainerBuilder
+var parameterReferenceRegexp = regexp.MustCompile(`\$\{[^}]+\}`)
+
 type ParametersAccessor interface {
 	GetString(key string) string
 	IsSet(key string) bool
+	IsSecret(key string) bool
+	// Returns names of all set parameters in alphabetical order
+	Keys() []string
 }
 
 type parametersBag struct {
 	mutex      sync.RWMutex
 	parameters map[string]string
+	secrets    map[string]bool
+	// Source parameter was loaded from, parameter is unset by reload of source which does not have it anymore
+	sources map[string]ParametersSource
 }
 
+// Parameter set directly is not owned by source anymore
 func (p *parametersBag) set(key string, value string) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
 	p.parameters[key] = value
+	delete(p.sources, key)
+}
+
+// Once parameter is marked as secret it stays secret even if it is overwritten by set()
+func (p *parametersBag) setSecret(key string, value string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.parameters[key] = value
+	p.secrets[key] = true
+	delete(p.sources, key)
+}
+
+// Sets all values loaded from source under one lock, so readers never see partially updated set of parameters.
+// Parameters loaded from source earlier and missing in values are unset. Source is nil if it can not be tracked.
+// Returns sorted keys of parameters which values were actually changed or unset.
+func (p *parametersBag) update(values map[string]string, secret bool, source ParametersSource) []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	changed := make([]string, 0)
+	if nil != source {
+		for key, keySource := range p.sources {
+			if _, isLoaded := values[key]; keySource == source && !isLoaded {
+				changed = append(changed, key)
+				delete(p.parameters, key)
+				delete(p.secrets, key)
+				delete(p.sources, key)
+			}
+		}
+	}
+	for key, value := range values {
+		if oldValue, isSet := p.parameters[key]; !isSet || oldValue != value {
+			changed = append(changed, key)
+		}
+		p.parameters[key] = value
+		if secret {
+			p.secrets[key] = true
+		}
+		if nil != source {
+			p.sources[key] = source
+		} else {
+			delete(p.sources, key)
+		}
+	}
+	sort.Strings(changed)
+
+	return changed
 }
 
 func (p *parametersBag) GetString(key string) string {
@@ -37,10 +102,126 @@ func (p *parametersBag) IsSet(key string) bool {
 	return isset
 }
 
+func (p *parametersBag) IsSecret(key string) bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.secrets[key]
+}
+
+func (p *parametersBag) Keys() []string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	keys := make([]string, 0, len(p.parameters))
+	for key := range p.parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Replaces ${name} references in values with values of referenced parameters. Parameter referencing secret
+// parameter becomes secret too. Returns errors for references to not set parameters and circular references.
+func (p *parametersBag) interpolate() []error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	interpolateErrors := make([]error, 0)
+	resolved := make(map[string]string)
+	resolving := make(map[string]bool)
+	// Parameters with invalid references, their errors are already reported
+	failed := make(map[string]bool)
+
+	var resolve func(key string, path []string) (string, bool)
+	resolve = func(key string, path []string) (string, bool) {
+		if value, isResolved := resolved[key]; isResolved {
+			return value, true
+		}
+		if failed[key] {
+			return "", false
+		}
+		path = append(path, key)
+		if resolving[key] {
+			interpolateErrors = append(
+				interpolateErrors,
+				fmt.Errorf("circular reference in parameters: %s", strings.Join(path, "->")),
+			)
+			return "", false
+		}
+
+		resolving[key] = true
+		defer delete(resolving, key)
+
+		isValid := true
+		value := parameterReferenceRegexp.ReplaceAllStringFunc(p.parameters[key], func(reference string) string {
+			referencedKey := reference[2 : len(reference)-1]
+			if _, isSet := p.parameters[referencedKey]; !isSet {
+				interpolateErrors = append(
+					interpolateErrors,
+					fmt.Errorf("parameter '%s' references not set parameter '%s'", key, referencedKey),
+				)
+				isValid = false
+				return reference
+			}
+
+			referencedValue, isResolved := resolve(referencedKey, path)
+			isValid = isValid && isResolved
+			if p.secrets[referencedKey] {
+				p.secrets[key] = true
+			}
+
+			return referencedValue
+		})
+		if isValid {
+			resolved[key] = value
+		} else {
+			failed[key] = true
+		}
+
+		return value, isValid
+	}
+
+	keys := make([]string, 0, len(p.parameters))
+	for key := range p.parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		resolve(key, nil)
+	}
+	for key, value := range resolved {
+		p.parameters[key] = value
+	}
+
+	return interpolateErrors
+}
+
+func (p *parametersBag) clone() *parametersBag {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	clone := newParametersBag()
+	for key, value := range p.parameters {
+		clone.parameters[key] = value
+	}
+	for key, isSecret := range p.secrets {
+		clone.secrets[key] = isSecret
+	}
+	for key, source := range p.sources {
+		clone.sources[key] = source
+	}
+
+	return clone
+}
+
 // ---------------------------------------------------------------------------------------------------------------------
 
 func newParametersBag() *parametersBag {
 	return &parametersBag{
 		parameters: make(map[string]string),
+		secrets:    make(map[string]bool),
+		sources:    make(map[string]ParametersSource),
 	}
 }
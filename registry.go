@@ -2,14 +2,80 @@ package gioc
 
 import (
 	"reflect"
+	"sort"
 	"sync"
+	"time"
 )
 
 type registryEntry struct {
 	factory        *Factory
 	cachingEnabled bool
-	cachedService  interface{}
 	id             int
+	decorators     []*Factory
+	tags           []string
+	// Private service can be injected as dependency, but is not returned by Container.GetByAlias and GetByObject
+	private bool
+	// Entry of ready-made instance (see Container.RegisterInstance), its factory just returns the instance
+	isInstance bool
+	// Parsed arguments definitions of factory and decorators, computed by ContainerBuilder.Build
+	plans map[*Factory][]*argumentDefinition
+
+	statsMutex    sync.Mutex
+	buildsCount   int
+	buildDuration time.Duration
+
+	// Cached instance is read by resolving goroutines and dropped by reloads and replacements. Generation is
+	// increased on every change of cache, so build started before the change does not cache its stale instance.
+	cacheMutex      sync.Mutex
+	cachedService   interface{}
+	cacheGeneration int
+}
+
+// Remembers duration of last factory run
+func (e *registryEntry) recordBuild(duration time.Duration) {
+	e.statsMutex.Lock()
+	defer e.statsMutex.Unlock()
+
+	e.buildsCount++
+	e.buildDuration = duration
+}
+
+func (e *registryEntry) buildStats() (int, time.Duration) {
+	e.statsMutex.Lock()
+	defer e.statsMutex.Unlock()
+
+	return e.buildsCount, e.buildDuration
+}
+
+// Returns cached instance (nil if there is none) and generation of cache
+func (e *registryEntry) cached() (interface{}, int) {
+	e.cacheMutex.Lock()
+	defer e.cacheMutex.Unlock()
+
+	return e.cachedService, e.cacheGeneration
+}
+
+// Caches instance built by build started at generation, instance is not cached if cache was changed since then
+func (e *registryEntry) cacheBuilt(service interface{}, generation int) {
+	e.cacheMutex.Lock()
+	defer e.cacheMutex.Unlock()
+
+	if generation == e.cacheGeneration {
+		e.cachedService = service
+	}
+}
+
+// Replaces cached instance, builds running at the moment do not overwrite it
+func (e *registryEntry) setCached(service interface{}) {
+	e.cacheMutex.Lock()
+	defer e.cacheMutex.Unlock()
+
+	e.cachedService = service
+	e.cacheGeneration++
+}
+
+func (e *registryEntry) dropCached() {
+	e.setCached(nil)
 }
 
 // ---------------------------------------------------------------------------------------------------------------------
@@ -21,53 +87,224 @@ type registry struct {
 	servicesCounter int
 }
 
-func (r *registry) writeAlias(alias string, entry *registryEntry) {
+// Returns entry previously registered with alias, nil if alias was free
+func (r *registry) writeAlias(alias string, entry *registryEntry) *registryEntry {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	r.servicesCounter++
-	entry.id = r.servicesCounter
+	previous := r.aliasIndex[alias]
+	r.assignId(entry)
 	r.aliasIndex[alias] = entry
+
+	return previous
 }
 
-func (r *registry) writeType(typeObj reflect.Type, entry *registryEntry) {
+// Returns entry previously registered with type, nil if type was free
+func (r *registry) writeType(typeObj reflect.Type, entry *registryEntry) *registryEntry {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	r.servicesCounter++
-	entry.id = r.servicesCounter
+	previous := r.typeIndex[typeObj]
+	r.assignId(entry)
 	r.typeIndex[typeObj] = entry
+
+	return previous
+}
+
+// Puts back entry returned by writeAlias, nil entry removes alias
+func (r *registry) restoreAlias(alias string, entry *registryEntry) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if nil == entry {
+		delete(r.aliasIndex, alias)
+	} else {
+		r.aliasIndex[alias] = entry
+	}
+}
+
+// Puts back entry returned by writeType, nil entry removes type
+func (r *registry) restoreType(typeObj reflect.Type, entry *registryEntry) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if nil == entry {
+		delete(r.typeIndex, typeObj)
+	} else {
+		r.typeIndex[typeObj] = entry
+	}
+}
+
+// Entry keeps its id when it is written with another alias, so ids follow registration order
+func (r *registry) assignId(entry *registryEntry) {
+	if 0 == entry.id {
+		r.servicesCounter++
+		entry.id = r.servicesCounter
+	}
 }
 
 func (r *registry) readAlias(alias string) *registryEntry {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	entry, entryExists := r.aliasIndex[alias]
-	if !entryExists {
-		panic("service with alias '" + alias + "' not found in Containers registry")
+	return r.aliasIndex[alias]
+}
+
+func (r *registry) readType(typeObj reflect.Type) *registryEntry {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.typeIndex[typeObj]
+}
+
+// Returns unique entries (entry with multiple aliases is returned once) ordered by id
+func (r *registry) entries() []*registryEntry {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	unique := make(map[*registryEntry]bool)
+	result := make([]*registryEntry, 0)
+	for _, entry := range r.aliasIndex {
+		if !unique[entry] {
+			unique[entry] = true
+			result = append(result, entry)
+		}
 	}
+	for _, entry := range r.typeIndex {
+		if !unique[entry] {
+			unique[entry] = true
+			result = append(result, entry)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].id < result[j].id
+	})
 
-	return entry
+	return result
 }
 
-func (r *registry) readType(typeObj reflect.Type) *registryEntry {
+// Returns sorted aliases and types each entry is registered with
+func (r *registry) keys() (map[*registryEntry][]string, map[*registryEntry][]reflect.Type) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	aliases := make(map[*registryEntry][]string)
+	for alias, entry := range r.aliasIndex {
+		aliases[entry] = append(aliases[entry], alias)
+	}
+	for _, entryAliases := range aliases {
+		sort.Strings(entryAliases)
+	}
+
+	types := make(map[*registryEntry][]reflect.Type)
+	for typeObj, entry := range r.typeIndex {
+		types[entry] = append(types[entry], typeObj)
+	}
+
+	return aliases, types
+}
+
+// Returns name of entry: first alias in alphabetical order or type if entry has no aliases
+func (r *registry) name(entry *registryEntry) string {
+	aliases, types := r.keys()
+
+	return entryName(aliases[entry], types[entry])
+}
+
+// Sets factory of entry, returns previous factory and instance flag
+func (r *registry) replaceFactory(entry *registryEntry, factory *Factory, isInstance bool) (*Factory, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	previous, previousIsInstance := entry.factory, entry.isInstance
+	entry.factory, entry.isInstance = factory, isInstance
+	entry.dropCached()
+
+	return previous, previousIsInstance
+}
+
+// Adds decorator and drops cached service, so next instance is decorated
+func (r *registry) addDecorator(entry *registryEntry, decorator *Factory) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry.decorators = append(entry.decorators, decorator)
+	entry.dropCached()
+}
+
+func (r *registry) removeLastDecorator(entry *registryEntry) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry.decorators = entry.decorators[:len(entry.decorators)-1]
+}
+
+// Adds tags entry does not have yet
+func (r *registry) addTags(entry *registryEntry, tags []string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, tag := range tags {
+		isTagged := false
+		for _, entryTag := range entry.tags {
+			isTagged = isTagged || entryTag == tag
+		}
+		if !isTagged {
+			entry.tags = append(entry.tags, tag)
+		}
+	}
+}
+
+func (r *registry) setPrivate(entry *registryEntry, private bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry.private = private
+}
+
+// Copies definitions without cached services. Entries are copied once, so aliases and types pointing to one entry
+// point to one copy too.
+func (r *registry) clone() *registry {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	entry, entryExists := r.typeIndex[typeObj]
-	if !entryExists {
-		panic("service with type '" + typeObj.String() + "' not found in Containers registry")
+	clone := newRegistry()
+	clone.servicesCounter = r.servicesCounter
+	copies := make(map[*registryEntry]*registryEntry)
+	copyEntry := func(entry *registryEntry) *registryEntry {
+		if entryCopy, isCopied := copies[entry]; isCopied {
+			return entryCopy
+		}
+
+		entryCopy := &registryEntry{
+			factory:        entry.factory,
+			cachingEnabled: entry.cachingEnabled,
+			id:             entry.id,
+			decorators:     append([]*Factory(nil), entry.decorators...),
+			tags:           append([]string(nil), entry.tags...),
+			private:        entry.private,
+			isInstance:     entry.isInstance,
+		}
+		copies[entry] = entryCopy
+
+		return entryCopy
+	}
+
+	for alias, entry := range r.aliasIndex {
+		clone.aliasIndex[alias] = copyEntry(entry)
+	}
+	for typeObj, entry := range r.typeIndex {
+		clone.typeIndex[typeObj] = copyEntry(entry)
 	}
 
-	return entry
+	return clone
 }
 
 func (r *registry) addServiceToCache(alias string, service interface{}) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	r.aliasIndex[alias].cachedService = service
+	r.aliasIndex[alias].setCached(service)
 }
 
 // ---------------------------------------------------------------------------------------------------------------------
@@ -78,3 +315,20 @@ func newRegistry() *registry {
 		typeIndex:  make(map[reflect.Type]*registryEntry, 0),
 	}
 }
+
+func entryName(aliases []string, types []reflect.Type) string {
+	if len(aliases) > 0 {
+		return aliases[0]
+	}
+
+	typeNames := make([]string, 0, len(types))
+	for _, typeObj := range types {
+		typeNames = append(typeNames, typeObj.String())
+	}
+	sort.Strings(typeNames)
+	if len(typeNames) > 0 {
+		return typeNames[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,182 @@
+package gioc
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// Reloadable can be implemented by services which need to react on parameters change without restart.
+// OnParametersChanged is called for cached (already instantiated) services after parameters were reloaded.
+type Reloadable interface {
+	OnParametersChanged(changed []string)
+}
+
+// ReloadTrigger tells ParametersWatcher when parameters source should be reloaded
+type ReloadTrigger interface {
+	// Start returns channel receiving value every time parameters should be reloaded
+	Start() <-chan struct{}
+	Stop()
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+// FilePollTrigger fires when modification time or size of file (or directory) changes. File is checked every Interval.
+type FilePollTrigger struct {
+	Path     string
+	Interval time.Duration
+	stopChan chan struct{}
+}
+
+func (t *FilePollTrigger) Start() <-chan struct{} {
+	triggerChan := make(chan struct{})
+	t.stopChan = make(chan struct{})
+	lastModTime, lastSize := t.stat()
+
+	go func() {
+		ticker := time.NewTicker(t.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				modTime, size := t.stat()
+				if modTime.Equal(lastModTime) && size == lastSize {
+					continue
+				}
+				lastModTime, lastSize = modTime, size
+
+				select {
+				case triggerChan <- struct{}{}:
+				case <-t.stopChan:
+					return
+				}
+			case <-t.stopChan:
+				return
+			}
+		}
+	}()
+
+	return triggerChan
+}
+
+func (t *FilePollTrigger) Stop() {
+	close(t.stopChan)
+}
+
+func (t *FilePollTrigger) stat() (time.Time, int64) {
+	fileInfo, statError := os.Stat(t.Path)
+	if nil != statError {
+		return time.Time{}, -1
+	}
+
+	return fileInfo.ModTime(), fileInfo.Size()
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+// SignalTrigger fires when process receives one of Signals (usually syscall.SIGHUP)
+type SignalTrigger struct {
+	Signals    []os.Signal
+	signalChan chan os.Signal
+	stopChan   chan struct{}
+}
+
+func (t *SignalTrigger) Start() <-chan struct{} {
+	triggerChan := make(chan struct{})
+	t.signalChan = make(chan os.Signal, 1)
+	t.stopChan = make(chan struct{})
+	signal.Notify(t.signalChan, t.Signals...)
+
+	go func() {
+		for {
+			select {
+			case <-t.signalChan:
+				select {
+				case triggerChan <- struct{}{}:
+				case <-t.stopChan:
+					return
+				}
+			case <-t.stopChan:
+				return
+			}
+		}
+	}()
+
+	return triggerChan
+}
+
+func (t *SignalTrigger) Stop() {
+	signal.Stop(t.signalChan)
+	close(t.stopChan)
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+// ParametersWatcher reloads parameters from source every time trigger fires. Created by Container.WatchParameters
+// and Container.WatchSecretParameters, stopped by Stop() or Container.Close().
+type ParametersWatcher struct {
+	container *Container
+	source    ParametersSource
+	trigger   ReloadTrigger
+	secret    bool
+	stopChan  chan struct{}
+	stopOnce  sync.Once
+
+	mutex     sync.RWMutex
+	lastError error
+}
+
+// Returns error of last reload, nil if last reload was successful
+func (w *ParametersWatcher) LastError() error {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	return w.lastError
+}
+
+func (w *ParametersWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		w.trigger.Stop()
+		close(w.stopChan)
+	})
+}
+
+func (w *ParametersWatcher) watch() {
+	triggerChan := w.trigger.Start()
+
+	go func() {
+		for {
+			select {
+			case <-triggerChan:
+				_, reloadError := w.container.reloadParameters(w.source, w.secret)
+				w.mutex.Lock()
+				w.lastError = reloadError
+				w.mutex.Unlock()
+			case <-w.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+func NewFilePollTrigger(path string, interval time.Duration) *FilePollTrigger {
+	return &FilePollTrigger{Path: path, Interval: interval}
+}
+
+func NewSignalTrigger(signals ...os.Signal) *SignalTrigger {
+	return &SignalTrigger{Signals: signals}
+}
+
+func newParametersWatcher(c *Container, source ParametersSource, trigger ReloadTrigger, secret bool) *ParametersWatcher {
+	return &ParametersWatcher{
+		container: c,
+		source:    source,
+		trigger:   trigger,
+		secret:    secret,
+		stopChan:  make(chan struct{}),
+	}
+}
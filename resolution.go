@@ -0,0 +1,80 @@
+package gioc
+
+import (
+	"sync"
+)
+
+// resolutionTracker keeps wait-for graph of services under construction. Every service is built in its own
+// goroutine (see taskManager), tracker knows which service each of these goroutines builds and which service
+// it waits for. Adding wait which closes a loop means that services wait for each other and will never be built.
+// Such loops are not visible to cycles checker when factories get services from Container inside Create.
+type resolutionTracker struct {
+	mutex sync.Mutex
+	// Goroutine id -> service this goroutine builds
+	building map[uint64]*registryEntry
+	// Service under construction -> service its factory waits for
+	waiting map[*registryEntry]*registryEntry
+}
+
+func (t *resolutionTracker) startBuilding(entry *registryEntry) uint64 {
+	goroutineId := currentGoroutineId()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.building[goroutineId] = entry
+
+	return goroutineId
+}
+
+func (t *resolutionTracker) stopBuilding(goroutineId uint64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.building, goroutineId)
+}
+
+// Registers that service built by current goroutine (if any) waits for dependency. Returns waiting service
+// (nil if current goroutine does not build a service) and services loop if waiting would never end.
+func (t *resolutionTracker) startWaiting(dependency *registryEntry) (*registryEntry, []*registryEntry) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	// Getting goroutine id is not free, it is not needed if no services are under construction
+	if 0 == len(t.building) {
+		return nil, nil
+	}
+
+	waiter, isBuilding := t.building[currentGoroutineId()]
+	if !isBuilding {
+		return nil, nil
+	}
+
+	loop := []*registryEntry{waiter}
+	for current := dependency; nil != current; current = t.waiting[current] {
+		loop = append(loop, current)
+		if current == waiter {
+			return waiter, loop
+		}
+	}
+
+	t.waiting[waiter] = dependency
+
+	return waiter, nil
+}
+
+func (t *resolutionTracker) stopWaiting(waiter *registryEntry) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.waiting, waiter)
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+func newResolutionTracker() *resolutionTracker {
+	return &resolutionTracker{
+		building: make(map[uint64]*registryEntry),
+		waiting:  make(map[*registryEntry]*registryEntry),
+	}
+}
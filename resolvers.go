@@ -0,0 +1,205 @@
+package gioc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrArgumentNotFound should be returned by ArgumentResolver.Resolve if value for argument does not exist.
+// In that case Container uses default value of argument definition (prefix:name|default) or zero value
+// for optional definitions (?prefix:name). Otherwise service instantiation fails.
+var ErrArgumentNotFound = errors.New("argument value not found")
+
+type DependencyKind int
+
+const (
+	// Dependency on service registered by type (argument without definition in Factory.Arguments)
+	TypeDependency DependencyKind = iota
+	// Dependency on service registered by alias
+	AliasDependency
+	// Dependency on Container's parameter
+	ParameterDependency
+)
+
+func (k DependencyKind) String() string {
+	switch k {
+	case TypeDependency:
+		return "type"
+	case AliasDependency:
+		return "alias"
+	case ParameterDependency:
+		return "parameter"
+	}
+
+	return "unknown"
+}
+
+func (k DependencyKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k *DependencyKind) UnmarshalText(text []byte) error {
+	for _, kind := range []DependencyKind{TypeDependency, AliasDependency, ParameterDependency} {
+		if kind.String() == string(text) {
+			*k = kind
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown dependency kind '%s'", string(text))
+}
+
+// Dependency describes edge of dependency graph created by factory argument
+type Dependency struct {
+	Kind      DependencyKind
+	Type      reflect.Type
+	Alias     string
+	Parameter string
+	// Position of factory argument which creates this dependency. Filled by Container.
+	Argument int
+	// Optional dependencies do not break service if dependency is missing. Container sets it for arguments with
+	// ?prefix or default value.
+	Optional bool
+	// Lazy dependencies are got after service is built (see Locator), so they do not create dependency cycles and
+	// are not built before service by Container.Warmup
+	Lazy bool
+	// Position of decorator (starting from 1) which argument creates this dependency, 0 for dependencies of service
+	// factory. Filled by Container.
+	Decorator int
+}
+
+// ArgumentResolver resolves argument definitions starting with prefix resolver was registered with.
+// Prefix is cut from definition before it is passed to resolver.
+type ArgumentResolver interface {
+	// Resolve returns value for argument. If returned value is a string which is not assignable to argumentType
+	// Container converts it to argumentType. If value does not exist ErrArgumentNotFound should be returned.
+	Resolve(c *Container, argument string, argumentType reflect.Type) (interface{}, error)
+	// Dependencies returns dependency edges created by argument. It must not instantiate anything,
+	// it is used by cycles checker and graph tooling.
+	Dependencies(c *Container, argument string) []Dependency
+}
+
+// SecretArgumentResolver can be implemented by ArgumentResolver to report that argument's value is secret.
+// Values of secret arguments are redacted in error messages produced by Container.
+type SecretArgumentResolver interface {
+	IsSecret(c *Container, argument string) bool
+}
+
+// ArgumentResolverFunc is an adapter to use ordinary function as ArgumentResolver which does not create dependencies
+// (for example for environment variables or secret files).
+type ArgumentResolverFunc func(c *Container, argument string, argumentType reflect.Type) (interface{}, error)
+
+func (f ArgumentResolverFunc) Resolve(c *Container, argument string, argumentType reflect.Type) (interface{}, error) {
+	return f(c, argument, argumentType)
+}
+
+func (f ArgumentResolverFunc) Dependencies(c *Container, argument string) []Dependency {
+	return nil
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+type serviceArgumentResolver struct{}
+
+func (r serviceArgumentResolver) Resolve(c *Container, argument string, argumentType reflect.Type) (interface{}, error) {
+	if entry, _ := c.lookupAlias(argument); nil == entry {
+		return nil, ErrArgumentNotFound
+	}
+
+	// Private services can be injected, so Container is asked directly
+	return c.getByAlias(argument), nil
+}
+
+func (r serviceArgumentResolver) Dependencies(c *Container, argument string) []Dependency {
+	return []Dependency{{Kind: AliasDependency, Alias: argument}}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+type parameterArgumentResolver struct{}
+
+func (r parameterArgumentResolver) Resolve(c *Container, argument string, argumentType reflect.Type) (interface{}, error) {
+	parameters := c.lookupParameters(argument)
+	if !parameters.IsSet(argument) {
+		return nil, ErrArgumentNotFound
+	}
+
+	return parameters.GetString(argument), nil
+}
+
+func (r parameterArgumentResolver) Dependencies(c *Container, argument string) []Dependency {
+	return []Dependency{{Kind: ParameterDependency, Parameter: argument}}
+}
+
+func (r parameterArgumentResolver) IsSecret(c *Container, argument string) bool {
+	return c.lookupParameters(argument).IsSecret(argument)
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+type argumentResolvers struct {
+	mutex     sync.RWMutex
+	resolvers map[string]ArgumentResolver
+	// Registered prefixes sorted from longest to shortest, so "file:" wins over "f"
+	prefixes []string
+}
+
+func (r *argumentResolvers) register(prefix string, resolver ArgumentResolver) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, isRegistered := r.resolvers[prefix]; !isRegistered {
+		r.prefixes = append(r.prefixes, prefix)
+		sort.SliceStable(r.prefixes, func(i, j int) bool {
+			return len(r.prefixes[i]) > len(r.prefixes[j])
+		})
+	}
+	r.resolvers[prefix] = resolver
+}
+
+func (r *argumentResolvers) match(definition string) (string, ArgumentResolver) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, prefix := range r.prefixes {
+		if strings.HasPrefix(definition, prefix) {
+			return prefix, r.resolvers[prefix]
+		}
+	}
+
+	return "", nil
+}
+
+func (r *argumentResolvers) clone() *argumentResolvers {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	clone := &argumentResolvers{
+		resolvers: make(map[string]ArgumentResolver, len(r.resolvers)),
+		prefixes:  append(make([]string, 0, len(r.prefixes)), r.prefixes...),
+	}
+	for prefix, resolver := range r.resolvers {
+		clone.resolvers[prefix] = resolver
+	}
+
+	return clone
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+func newArgumentResolvers() *argumentResolvers {
+	r := &argumentResolvers{
+		resolvers: make(map[string]ArgumentResolver),
+		prefixes:  make([]string, 0),
+	}
+	r.register(argumentPrefixService, serviceArgumentResolver{})
+	r.register(argumentPrefixParameter, parameterArgumentResolver{})
+	r.register(argumentPrefixLocator, locatorArgumentResolver{})
+
+	return r
+}
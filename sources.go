@@ -0,0 +1,120 @@
+package gioc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Directory where Docker and Kubernetes mount secrets by default
+const DefaultSecretsDir = "/run/secrets"
+
+// ParametersSource provides parameters for Container (see Container.LoadParameters and Container.LoadSecretParameters)
+type ParametersSource interface {
+	Parameters() (map[string]string, error)
+}
+
+// Returns source if parameters loaded from it can be tracked (see Container.ReloadParameters), nil otherwise.
+// Source is tracked by value, so sources which can not be compared (like maps) are not tracked.
+func trackedSource(source ParametersSource) ParametersSource {
+	if !reflect.TypeOf(source).Comparable() {
+		return nil
+	}
+
+	return source
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+// FileSource reads parameters from file with "key=value" lines. Empty lines and lines starting with "#" are skipped,
+// spaces around keys and values are trimmed.
+type FileSource struct {
+	Path string
+}
+
+func (s *FileSource) Parameters() (map[string]string, error) {
+	content, readError := ioutil.ReadFile(s.Path)
+	if nil != readError {
+		return nil, readError
+	}
+
+	result := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if "" == line || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		delimPos := strings.Index(line, "=")
+		if delimPos < 0 {
+			return nil, errors.New(fmt.Sprintf("%s:%d: line is not a key=value pair", s.Path, lineNum))
+		}
+		result[strings.TrimSpace(line[:delimPos])] = strings.TrimSpace(line[delimPos+1:])
+	}
+
+	return result, scanner.Err()
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+// SecretFilesSource reads secrets from directory with one file per secret (Docker/Kubernetes style /run/secrets/<name>).
+// File name (with Prefix prepended) is used as parameter name, file content without trailing line breaks as value.
+// Hidden files and directories (like Kubernetes' "..data") are skipped.
+type SecretFilesSource struct {
+	Dir    string
+	Prefix string
+}
+
+func (s *SecretFilesSource) Parameters() (map[string]string, error) {
+	files, readDirError := ioutil.ReadDir(s.Dir)
+	if nil != readDirError {
+		return nil, readDirError
+	}
+
+	result := make(map[string]string)
+	for _, file := range files {
+		if strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+
+		filePath := filepath.Join(s.Dir, file.Name())
+		// Stat follows symlinks, Kubernetes mounts secrets as symlinks to files in "..data" directory
+		fileInfo, statError := os.Stat(filePath)
+		if nil != statError {
+			return nil, statError
+		}
+		if !fileInfo.Mode().IsRegular() {
+			continue
+		}
+
+		content, readError := ioutil.ReadFile(filePath)
+		if nil != readError {
+			return nil, readError
+		}
+
+		result[s.Prefix+file.Name()] = strings.TrimRight(string(content), "\r\n")
+	}
+
+	return result, nil
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+func NewFileSource(path string) *FileSource {
+	return &FileSource{Path: path}
+}
+
+func NewSecretFilesSource(dir string) *SecretFilesSource {
+	if "" == dir {
+		dir = DefaultSecretsDir
+	}
+
+	return &SecretFilesSource{Dir: dir}
+}
@@ -1,12 +1,13 @@
 package gioc
 
 import (
+	"fmt"
 	"sync"
 )
 
 type taskManager struct {
 	addTaskChan    chan *taskDefinition
-	removeTaskChan chan *taskDefinition
+	removeTaskChan chan *completedTask
 	stopServeChan  chan bool
 
 	onServeMutex sync.Mutex
@@ -47,19 +48,20 @@ func (tm *taskManager) serve() {
 				// Run task
 				runningTasksListeners.append(newTaskDef.taskName, newTaskDef.listener)
 				go func() {
-					result, taskError := newTaskDef.perform()
-					taskResultObj := &taskResult{
-						result:    result,
-						taskError: taskError,
+					result, taskError := performTask(newTaskDef)
+					tm.removeTaskChan <- &completedTask{
+						task:   newTaskDef,
+						result: &taskResult{result: result, taskError: taskError},
 					}
-					listeners, _ := runningTasksListeners.get(newTaskDef.taskName)
-					for _, listener := range listeners {
-						listener <- taskResultObj
-					}
-					tm.removeTaskChan <- newTaskDef
 				}()
 			case processedTask := <-tm.removeTaskChan:
-				runningTasksListeners.delete(processedTask.taskName)
+				// Listeners are notified here, not in task goroutine, so listener added while task was finishing
+				// is not lost. Listeners channels are buffered, so sending does not block.
+				listeners, _ := runningTasksListeners.get(processedTask.task.taskName)
+				for _, listener := range listeners {
+					listener <- processedTask.result
+				}
+				runningTasksListeners.delete(processedTask.task.taskName)
 			case <-tm.stopServeChan:
 				// This stopServeChan channel used to stop this goroutine after stopServe() call if no task are running
 				tm.onServe = false
@@ -84,6 +86,17 @@ type taskDefinition struct {
 	perform  func() (interface{}, error)
 }
 
+// Panic in task goroutine can not be recovered by code waiting for task result, so it is returned as task error
+func performTask(task *taskDefinition) (result interface{}, taskError error) {
+	defer func() {
+		if recovered := recover(); nil != recovered {
+			result, taskError = nil, fmt.Errorf("%v", recovered)
+		}
+	}()
+
+	return task.perform()
+}
+
 // --------------------------------------------
 
 type taskResult struct {
@@ -91,6 +104,11 @@ type taskResult struct {
 	taskError error
 }
 
+type completedTask struct {
+	task   *taskDefinition
+	result *taskResult
+}
+
 // --------------------------------------------
 
 type runningTasksListenersMap struct {
@@ -139,7 +157,7 @@ func newRunningTasksListenersMap() *runningTasksListenersMap {
 func newTaskManager() *taskManager {
 	return &taskManager{
 		addTaskChan:    make(chan *taskDefinition),
-		removeTaskChan: make(chan *taskDefinition),
+		removeTaskChan: make(chan *completedTask),
 		stopServeChan:  make(chan bool),
 		onServe:        true,
 	}
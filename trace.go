@@ -0,0 +1,164 @@
+package gioc
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// traceEvent is an event of Chrome trace event format, see
+// https://docs.google.com/document/d/1CvAClvFfyA5R-PhYUmn5OOQtYMH4h6I0nSsKchNAySU
+type traceEvent struct {
+	Name     string `json:"name"`
+	Category string `json:"cat,omitempty"`
+	Phase    string `json:"ph"`
+	// Timestamp and duration in microseconds
+	Timestamp float64                `json:"ts"`
+	Duration  float64                `json:"dur,omitempty"`
+	ProcessId int                    `json:"pid"`
+	ThreadId  uint64                 `json:"tid"`
+	Args      map[string]interface{} `json:"args,omitempty"`
+}
+
+type traceDocument struct {
+	TraceEvents     []traceEvent `json:"traceEvents"`
+	DisplayTimeUnit string       `json:"displayTimeUnit"`
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+// traceRecorder records resolution events as Chrome trace events. Every goroutine is shown as separate thread:
+// "resolve" events are recorded on goroutine which requested service and show how long it waited for the service,
+// "factory" events are recorded on goroutine which built service and show duration of factory call.
+// Observer callbacks are called synchronously, so goroutine of callback is goroutine of event.
+type traceRecorder struct {
+	NopObserver
+	started time.Time
+
+	mutex  sync.Mutex
+	events []traceEvent
+	// Start time of events by goroutine id and event name
+	starts map[uint64]map[string]time.Time
+}
+
+func (r *traceRecorder) ResolveStart(event ServiceEvent) {
+	r.start("resolve", event)
+}
+
+func (r *traceRecorder) ResolveEnd(event ServiceEvent) {
+	r.end("resolve", event)
+}
+
+func (r *traceRecorder) FactoryStart(event ServiceEvent) {
+	r.start("factory", event)
+}
+
+func (r *traceRecorder) FactoryEnd(event ServiceEvent) {
+	r.end("factory", event)
+}
+
+func (r *traceRecorder) start(category string, event ServiceEvent) {
+	goroutineId := currentGoroutineId()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if nil == r.starts[goroutineId] {
+		r.starts[goroutineId] = make(map[string]time.Time)
+	}
+	r.starts[goroutineId][category+" "+event.Service] = time.Now()
+}
+
+func (r *traceRecorder) end(category string, event ServiceEvent) {
+	goroutineId := currentGoroutineId()
+	finished := time.Now()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	name := category + " " + event.Service
+	started, isStarted := r.starts[goroutineId][name]
+	if !isStarted {
+		return
+	}
+	delete(r.starts[goroutineId], name)
+
+	args := map[string]interface{}{"service_id": event.ServiceID}
+	if nil != event.Error {
+		args["error"] = event.Error.Error()
+	}
+	r.events = append(
+		r.events,
+		traceEvent{
+			Name:      name,
+			Category:  category,
+			Phase:     "X",
+			Timestamp: r.microseconds(started.Sub(r.started)),
+			Duration:  r.microseconds(finished.Sub(started)),
+			ProcessId: 1,
+			ThreadId:  goroutineId,
+			Args:      args,
+		},
+	)
+}
+
+func (r *traceRecorder) microseconds(duration time.Duration) float64 {
+	return float64(duration.Nanoseconds()) / 1000
+}
+
+// Writes events ordered by start time, preceded by names of goroutines
+func (r *traceRecorder) write(w io.Writer) error {
+	r.mutex.Lock()
+	events := make([]traceEvent, len(r.events))
+	copy(events, r.events)
+	r.mutex.Unlock()
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp < events[j].Timestamp
+	})
+
+	goroutineIds := make([]uint64, 0)
+	isNamed := make(map[uint64]bool)
+	for _, event := range events {
+		if !isNamed[event.ThreadId] {
+			isNamed[event.ThreadId] = true
+			goroutineIds = append(goroutineIds, event.ThreadId)
+		}
+	}
+	sort.Slice(goroutineIds, func(i, j int) bool {
+		return goroutineIds[i] < goroutineIds[j]
+	})
+
+	document := traceDocument{
+		TraceEvents:     make([]traceEvent, 0, len(goroutineIds)+len(events)),
+		DisplayTimeUnit: "ms",
+	}
+	for _, goroutineId := range goroutineIds {
+		document.TraceEvents = append(
+			document.TraceEvents,
+			traceEvent{
+				Name:      "thread_name",
+				Phase:     "M",
+				ProcessId: 1,
+				ThreadId:  goroutineId,
+				Args:      map[string]interface{}{"name": "goroutine " + strconv.FormatUint(goroutineId, 10)},
+			},
+		)
+	}
+	document.TraceEvents = append(document.TraceEvents, events...)
+
+	return json.NewEncoder(w).Encode(document)
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+func newTraceRecorder() *traceRecorder {
+	return &traceRecorder{
+		started: time.Now(),
+		events:  make([]traceEvent, 0),
+		starts:  make(map[uint64]map[string]time.Time),
+	}
+}
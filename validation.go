@@ -0,0 +1,183 @@
+package gioc
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ValidationError describes one problem found by Container.Validate
+type ValidationError struct {
+	// Name of service with the problem (see GraphNode.Name)
+	Service string
+	// Position of factory argument with the problem, -1 if problem is not related to one argument
+	Argument int
+	// Dependency cycle, only for cycle errors
+	Cycle   Cycle
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Argument >= 0 {
+		return fmt.Sprintf("service '%s', argument %d: %s", e.Service, e.Argument, e.Message)
+	}
+
+	return fmt.Sprintf("service '%s': %s", e.Service, e.Message)
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+type containerValidator struct {
+	container *Container
+	errors    []error
+}
+
+func (v *containerValidator) addError(service string, argument int, format string, args ...interface{}) {
+	v.errors = append(
+		v.errors,
+		&ValidationError{Service: service, Argument: argument, Message: fmt.Sprintf(format, args...)},
+	)
+}
+
+func (v *containerValidator) validateEntry(entry *registryEntry, serviceName string) {
+	v.validateFactory(entry.factory, 0, serviceName, "")
+	for decoratorNum, decorator := range entry.decorators {
+		v.validateFactory(decorator, 1, serviceName, fmt.Sprintf("decorator %d: ", decoratorNum+1))
+	}
+}
+
+// Validates factory (or decorator) arguments starting from firstArgument, see Container.resolveFactoryArguments
+func (v *containerValidator) validateFactory(factory *Factory, firstArgument int, serviceName string, messagePrefix string) {
+	factoryMethodType := reflect.TypeOf(factory.Create)
+
+	// Definitions of variadic factory following its fixed arguments describe elements of variadic argument
+	if !factoryMethodType.IsVariadic() && len(factory.Arguments) > factoryMethodType.NumIn()-firstArgument {
+		v.addError(
+			serviceName,
+			-1,
+			"%sfactory has %d arguments, but %d argument definitions given",
+			messagePrefix,
+			factoryMethodType.NumIn()-firstArgument,
+			len(factory.Arguments),
+		)
+	}
+
+	for _, argument := range factoryCallArguments(factory, firstArgument) {
+		if argument.definitionNum < 0 {
+			if entry, _ := v.container.lookupType(argument.argumentType); nil == entry {
+				v.addError(
+					serviceName,
+					argument.position,
+					"%sfactory for service with type %s not registered",
+					messagePrefix,
+					argument.argumentType.String(),
+				)
+			}
+			continue
+		}
+
+		definition := parseArgumentDefinition(v.container.resolvers, factory.Arguments[argument.definitionNum])
+		if definition.isLiteral() {
+			if _, conversionError := v.container.convertArgument(argument.argumentType, definition.name, false); nil != conversionError {
+				v.addError(serviceName, argument.position, "%s%s", messagePrefix, conversionError.Error())
+			}
+			continue
+		}
+
+		v.validateDefinition(definition, argument.argumentType, serviceName, argument.position, messagePrefix)
+	}
+}
+
+func (v *containerValidator) validateDefinition(
+	definition *argumentDefinition,
+	argumentType reflect.Type,
+	serviceName string,
+	argumentNum int,
+	messagePrefix string,
+) {
+	c := v.container
+	canBeMissing := definition.optional || definition.hasDefault
+
+	if _, isLocatorResolver := definition.resolver.(locatorArgumentResolver); isLocatorResolver {
+		if typeError := checkLocatorArgumentType(argumentType); nil != typeError {
+			v.addError(serviceName, argumentNum, "%s%s", messagePrefix, typeError.Error())
+
+			return
+		}
+	}
+
+	for _, dependency := range definition.resolver.Dependencies(c, definition.name) {
+		switch dependency.Kind {
+		case TypeDependency:
+			if entry, _ := c.lookupType(dependency.Type); nil == entry && !canBeMissing {
+				v.addError(serviceName, argumentNum, "%sfactory for service with type %s not registered", messagePrefix, dependency.Type.String())
+			}
+		case AliasDependency:
+			if entry, _ := c.lookupAlias(dependency.Alias); nil == entry && !canBeMissing {
+				v.addError(serviceName, argumentNum, "%sservice with alias '%s' not registered", messagePrefix, dependency.Alias)
+			}
+		case ParameterDependency:
+			parameters := c.lookupParameters(dependency.Parameter)
+			if !parameters.IsSet(dependency.Parameter) {
+				if !canBeMissing {
+					v.addError(serviceName, argumentNum, "%sparameter '%s' not set", messagePrefix, dependency.Parameter)
+				}
+				continue
+			}
+
+			// Value of parameter is known only for parameters resolver, custom resolvers can transform it
+			if _, isParameterResolver := definition.resolver.(parameterArgumentResolver); !isParameterResolver {
+				continue
+			}
+			_, conversionError := c.convertArgument(
+				argumentType,
+				parameters.GetString(dependency.Parameter),
+				parameters.IsSecret(dependency.Parameter),
+			)
+			if nil != conversionError {
+				v.addError(serviceName, argumentNum, "%sparameter '%s': %s", messagePrefix, dependency.Parameter, conversionError.Error())
+			}
+		}
+	}
+
+	if definition.hasDefault {
+		if _, conversionError := c.convertArgument(argumentType, definition.defaultValue, false); nil != conversionError {
+			v.addError(serviceName, argumentNum, "%sdefault value: %s", messagePrefix, conversionError.Error())
+		}
+	}
+}
+
+func (v *containerValidator) validateCycles(graph *Graph) {
+	for _, cycle := range newCheckerTable(graph).cycles() {
+		v.errors = append(
+			v.errors,
+			&ValidationError{
+				Service:  cycle[0],
+				Argument: -1,
+				Cycle:    cycle,
+				Message:  "dependency cycle " + cycle.String(),
+			},
+		)
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+func validateContainer(c *Container) []error {
+	validator := &containerValidator{
+		container: c,
+		errors:    make([]error, 0),
+	}
+
+	graph := buildGraph(c)
+	names := make(map[int]string, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		names[node.ID] = node.Name
+	}
+
+	for _, entry := range c.registry.entries() {
+		validator.validateEntry(entry, names[entry.id])
+	}
+	validator.validateCycles(graph)
+
+	return validator.errors
+}
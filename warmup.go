@@ -0,0 +1,211 @@
+package gioc
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WarmupError is returned by Container.Warmup, it contains errors of all services which failed to build
+type WarmupError struct {
+	Errors []error
+}
+
+func (e *WarmupError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, serviceError := range e.Errors {
+		messages = append(messages, serviceError.Error())
+	}
+
+	return fmt.Sprintf("Warmup failed with %d errors:\n", len(e.Errors)) + strings.Join(messages, "\n")
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+type warmupNode struct {
+	entry        *registryEntry
+	name         string
+	dependencies []int
+	dependents   []int
+	// Number of dependencies which are not built yet, node is ready to build when it is 0
+	pending int
+	// Name of service which failed to build and which this node depends on (directly or through not cached services)
+	failedDependency string
+}
+
+type containerWarmup struct {
+	container *Container
+	nodes     map[int]*warmupNode
+
+	mutex  sync.Mutex
+	errors map[int]error
+}
+
+// Dependencies are built before node is ready, so their errors are already known
+func (w *containerWarmup) skipOnFailedDependency(node *warmupNode) bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for _, dependencyId := range node.dependencies {
+		dependency := w.nodes[dependencyId]
+		if "" != dependency.failedDependency {
+			node.failedDependency = dependency.failedDependency
+		} else if _, hasFailed := w.errors[dependencyId]; hasFailed {
+			node.failedDependency = dependency.name
+		}
+	}
+	if "" == node.failedDependency {
+		return false
+	}
+
+	if node.entry.cachingEnabled {
+		w.errors[node.entry.id] = fmt.Errorf(
+			"service '%s': not built because dependency '%s' failed",
+			node.name,
+			node.failedDependency,
+		)
+	}
+
+	return true
+}
+
+// Builds services with Kahn's algorithm: service starts as soon as all its dependencies are built, so slow service
+// delays only services depending on it. At most workers services are built at once.
+func (w *containerWarmup) build(ctx context.Context, ready []*warmupNode, workers int) {
+	built := make(chan *warmupNode)
+	running := 0
+
+	// Marks node as built and moves dependents which do not wait for other dependencies to ready queue
+	complete := func(node *warmupNode) {
+		for _, dependentId := range node.dependents {
+			dependent := w.nodes[dependentId]
+			dependent.pending--
+			if 0 == dependent.pending {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	for {
+		for len(ready) > 0 && running < workers && nil == ctx.Err() {
+			// Ready nodes are started in order of ids, so result does not depend on goroutines scheduling
+			sort.Slice(ready, func(i, j int) bool {
+				return ready[i].entry.id < ready[j].entry.id
+			})
+			node := ready[0]
+			ready = ready[1:]
+
+			// Not cached services are built every time they are requested, building them now is useless
+			if w.skipOnFailedDependency(node) || !node.entry.cachingEnabled {
+				complete(node)
+				continue
+			}
+
+			running++
+			go func(node *warmupNode) {
+				if _, buildError := w.container.getByRegistryEntry(node.entry); nil != buildError {
+					w.mutex.Lock()
+					w.errors[node.entry.id] = fmt.Errorf("service '%s': %s", node.name, buildError.Error())
+					w.mutex.Unlock()
+				}
+				built <- node
+			}(node)
+		}
+
+		if 0 == running {
+			return
+		}
+
+		select {
+		case node := <-built:
+			running--
+			complete(node)
+		case <-ctx.Done():
+			// Services being built can not be interrupted, waiting for them
+			for ; running > 0; running-- {
+				<-built
+			}
+
+			return
+		}
+	}
+}
+
+func (w *containerWarmup) run(ctx context.Context, workers int) error {
+	graph := buildGraph(w.container)
+
+	if cycles := newCheckerTable(graph).cycles(); len(cycles) > 0 {
+		cycleErrors := make([]error, 0, len(cycles))
+		for _, cycle := range cycles {
+			cycleErrors = append(cycleErrors, fmt.Errorf("service '%s': dependency cycle %s", cycle[0], cycle.String()))
+		}
+
+		return &WarmupError{Errors: cycleErrors}
+	}
+
+	entries := make(map[int]*registryEntry)
+	for _, entry := range w.container.registry.entries() {
+		entries[entry.id] = entry
+	}
+	for _, graphNode := range graph.Nodes {
+		w.nodes[graphNode.ID] = &warmupNode{
+			entry:        entries[graphNode.ID],
+			name:         graphNode.Name,
+			dependencies: make([]int, 0),
+			dependents:   make([]int, 0),
+		}
+	}
+	for _, edge := range graph.Edges {
+		if ParameterDependency != edge.Kind && !edge.Lazy && 0 != edge.To {
+			w.nodes[edge.From].dependencies = append(w.nodes[edge.From].dependencies, edge.To)
+			w.nodes[edge.From].pending++
+			w.nodes[edge.To].dependents = append(w.nodes[edge.To].dependents, edge.From)
+		}
+	}
+
+	ready := make([]*warmupNode, 0)
+	for _, graphNode := range graph.Nodes {
+		if node := w.nodes[graphNode.ID]; 0 == node.pending {
+			ready = append(ready, node)
+		}
+	}
+	w.build(ctx, ready, workers)
+
+	ids := make([]int, 0, len(w.errors))
+	for id := range w.errors {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	warmupErrors := make([]error, 0, len(ids)+1)
+	for _, id := range ids {
+		warmupErrors = append(warmupErrors, w.errors[id])
+	}
+	if nil != ctx.Err() {
+		warmupErrors = append(warmupErrors, ctx.Err())
+	}
+
+	if 0 == len(warmupErrors) {
+		return nil
+	}
+
+	return &WarmupError{Errors: warmupErrors}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+func warmupContainer(ctx context.Context, c *Container, workers int) error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	warmup := &containerWarmup{
+		container: c,
+		nodes:     make(map[int]*warmupNode),
+		errors:    make(map[int]error),
+	}
+
+	return warmup.run(ctx, workers)
+}